@@ -0,0 +1,514 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/jfhaecker/mandelgo/floatexp"
+	"github.com/jfhaecker/mandelgo/fractal"
+	"github.com/jfhaecker/mandelgo/palette"
+	"github.com/jfhaecker/mandelgo/render"
+	"github.com/jfhaecker/mandelgo/viewport"
+)
+
+// RenderConfig holds every parameter that controls a run.
+type RenderConfig struct {
+	ImageWidth    int
+	ImageHeight   int
+	MaxIter       int
+	IterPolicy    string
+	MaxIterLimit  int
+	DepthBase     float64
+	DepthExponent float64
+	AdaptTarget   float64 // fraction of pixels at maxiter
+	AdaptGrowth   float64
+	Periodicity   float64
+	Strategy      string
+	BailoutRadius float64
+	MandelWorkers int
+	ImageCount    int
+	StartLocation int
+	ScaleRatio    float64
+	Center        viewport.BigPoint
+	Radius        floatexp.Float
+	Palette       string
+	PaletteFiles  []string
+	Interpolation string // overrides the color space of the palette
+	Easing        string // overrides the easing of the palette
+	Histogram     bool
+	HistSmoothing float64
+	Distance      bool
+	Boundary      float64
+	BoundaryColor string
+	Shading       float64
+	Trap          string // orbit trap shape, empty for none
+	TrapCenter    complex128
+	TrapAngle     float64 // degrees
+	TrapSize      float64 // circle radius or image width
+	TrapImage     string
+	TrapScale     float64
+	Output        string
+	Buffer        string
+	NPY           string
+	Raw           string
+	Resume        bool
+	OnError       string
+	Retries       int
+	Fractal       string
+	C             complex128
+	Formula       string
+	Degree        float64
+	Polynomial    string
+	Tolerance     float64
+
+	trapImage image.Image
+}
+
+// The fractal types.
+const (
+	fractalMandelbrot = "mandelbrot"
+	fractalJulia      = "julia"
+	fractalNewton     = "newton"
+)
+
+// How MaxIter changes from frame to frame.
+const (
+	iterFixed    = "fixed"
+	iterDepth    = "depth"
+	iterAdaptive = "adaptive"
+)
+
+// What to do when an image cannot be written.
+const (
+	onErrorAbort = "abort"
+	onErrorRetry = "retry"
+	onErrorSkip  = "skip"
+)
+
+func newRenderConfig() *RenderConfig {
+	return &RenderConfig{
+		ImageWidth:    1000,
+		ImageHeight:   1000,
+		MaxIter:       1000,
+		IterPolicy:    iterFixed,
+		MaxIterLimit:  100000,
+		DepthBase:     50,
+		DepthExponent: 1.25,
+		AdaptTarget:   0.1,
+		AdaptGrowth:   1.25,
+		Periodicity:   1e-9,
+		Strategy:      "tiles",
+		BailoutRadius: 20,
+		MandelWorkers: runtime.GOMAXPROCS(0),
+		ImageCount:    650,
+		StartLocation: 18,
+		ScaleRatio:    0.03,
+		Center:        viewport.Locations[18].Center(),
+		Radius:        floatexp.New(0.05),
+		Palette:       "quake",
+		BoundaryColor: "black",
+		TrapSize:      1,
+		TrapScale:     1,
+		Output:        "mandel-%03v.png",
+		OnError:       onErrorAbort,
+		Retries:       3,
+		Fractal:       fractalMandelbrot,
+		Formula:       "mandelbrot",
+		Degree:        3,
+		Polynomial:    "z^3-1",
+		Tolerance:     1e-6,
+	}
+}
+
+// parseFlags fills a RenderConfig from the command line. flag.ErrHelp is
+// returned when -h or --help was given.
+func parseFlags(args []string, output io.Writer) (*RenderConfig, error) {
+	cfg := newRenderConfig()
+	fs := flag.NewFlagSet("mandelgo", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: mandelgo [flags]\n")
+		fmt.Fprintf(fs.Output(), "       mandelgo render [-workers n] [-resume] job.(yaml|json|toml)\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.IntVar(&cfg.ImageWidth, "width", cfg.ImageWidth, "image width in pixels")
+	fs.IntVar(&cfg.ImageHeight, "height", cfg.ImageHeight, "image height in pixels")
+	fs.IntVar(&cfg.MaxIter, "maxiter", cfg.MaxIter, "maximum number of iterations per point")
+	fs.StringVar(&cfg.IterPolicy, "iterations", cfg.IterPolicy,
+		"maxiter per frame: fixed, depth (grows with the zoom) or adaptive (grows while many pixels reach it)")
+	fs.IntVar(&cfg.MaxIterLimit, "maxiter-limit", cfg.MaxIterLimit, "upper limit for -iterations depth and adaptive, 0 for none")
+	fs.Float64Var(&cfg.DepthBase, "depth-base", cfg.DepthBase, "-iterations depth: base*log10(zoom)^exponent")
+	fs.Float64Var(&cfg.DepthExponent, "depth-exponent", cfg.DepthExponent, "-iterations depth: base*log10(zoom)^exponent")
+	fs.Float64Var(&cfg.AdaptTarget, "adaptive-target", cfg.AdaptTarget,
+		"-iterations adaptive grows once more than this fraction of the pixels reach maxiter")
+	fs.Float64Var(&cfg.AdaptGrowth, "adaptive-growth", cfg.AdaptGrowth, "-iterations adaptive multiplies maxiter by this")
+	fs.Float64Var(&cfg.Periodicity, "periodicity", cfg.Periodicity,
+		"tolerance in pixels of the cycle detection that stops interior points early, 0 disables it")
+	fs.StringVar(&cfg.Strategy, "strategy", cfg.Strategy,
+		"how pixels are computed: "+strings.Join(render.StrategyNames, ", "))
+	fs.Float64Var(&cfg.BailoutRadius, "bailout", cfg.BailoutRadius, "escape radius, must be at least 2")
+	fs.BoolVar(&cfg.Distance, "distance", cfg.Distance,
+		"estimate the distance to the set, -boundary and -shading turn it on")
+	fs.IntVar(&cfg.MandelWorkers, "workers", cfg.MandelWorkers, "number of mandel workers")
+	fs.IntVar(&cfg.ImageCount, "count", cfg.ImageCount, "number of images to render")
+	fs.IntVar(&cfg.StartLocation, "location", cfg.StartLocation,
+		fmt.Sprintf("index of the start location (0-%v)", len(viewport.Locations)-1))
+	fs.Float64Var(&cfg.ScaleRatio, "scale", cfg.ScaleRatio, "zoom ratio applied after each image")
+	fs.Func("radius", "half width of the first image in the complex plane, may go below 1e-308 (default 0.05)",
+		func(v string) error {
+			r, err := floatexp.ParseFloat(v)
+			cfg.Radius = r
+			return err
+		})
+	addColoringFlags(fs, cfg)
+	fs.StringVar(&cfg.Output, "output", cfg.Output, "output file name pattern, gets the image number")
+	fs.StringVar(&cfg.Buffer, "buffer", cfg.Buffer,
+		"also save the iteration data for recolor, file name pattern like mandel-%03v.mgb")
+	fs.StringVar(&cfg.NPY, "npy", cfg.NPY, "also export the iteration data as NumPy array, file name pattern like mandel-%03v.npy")
+	fs.StringVar(&cfg.Raw, "raw", cfg.Raw,
+		"also export the iteration data as compressed raw arrays with a .json sidecar, file name pattern like mandel-%03v.raw")
+	fs.BoolVar(&cfg.Resume, "resume", false, "skip images that are already rendered")
+	fs.StringVar(&cfg.OnError, "on-error", cfg.OnError, "when an image cannot be written: abort, retry or skip")
+	fs.IntVar(&cfg.Retries, "retries", cfg.Retries, "number of retries for -on-error retry")
+	fs.StringVar(&cfg.Fractal, "fractal", cfg.Fractal, "fractal type: mandelbrot, julia or newton")
+	fs.StringVar(&cfg.Formula, "formula", cfg.Formula,
+		"iteration formula: "+strings.Join(fractal.FormulaNames, ", "))
+	fs.Float64Var(&cfg.Degree, "degree", cfg.Degree, "exponent of the multibrot formula")
+	fs.StringVar(&cfg.Polynomial, "polynomial", cfg.Polynomial,
+		"newton polynomial like z^3-1 or coefficients like 1,0,0,-1")
+	fs.Float64Var(&cfg.Tolerance, "tolerance", cfg.Tolerance, "newton convergence tolerance")
+	centerSet := false
+	fs.Func("center", "center of the first image like -0.75+0.1i, overrides -location", func(v string) error {
+		c, err := viewport.ParseBigPoint(v)
+		cfg.Center, centerSet = c, true
+		return err
+	})
+	fs.Func("c", "julia constant like -0.8+0.156i", func(v string) error {
+		c, err := strconv.ParseComplex(v, 128)
+		cfg.C = c
+		return err
+	})
+	fs.Func("c-location", "take the julia constant from the center of a location", func(v string) error {
+		c, err := locationCenter(v)
+		cfg.C = c
+		return err
+	})
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+	if !centerSet && cfg.StartLocation >= 0 && cfg.StartLocation < len(viewport.Locations) {
+		cfg.Center = viewport.Locations[cfg.StartLocation].Center()
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *RenderConfig) Validate() error {
+	switch {
+	case c.ImageWidth < 2:
+		return errors.New("width must be at least 2")
+	case c.ImageHeight < 2:
+		return errors.New("height must be at least 2")
+	case c.MaxIter < 1:
+		return errors.New("maxiter must be positive")
+	case c.MaxIterLimit < 0:
+		return errors.New("maxiter-limit must not be negative")
+	case c.DepthBase <= 0 || c.DepthExponent <= 0:
+		return errors.New("depth-base and depth-exponent must be positive")
+	case c.AdaptTarget < 0 || c.AdaptTarget >= 1:
+		return errors.New("adaptive-target must be in [0, 1)")
+	case c.AdaptGrowth <= 1:
+		return errors.New("adaptive-growth must be greater than 1")
+	case c.Periodicity < 0:
+		return errors.New("periodicity must not be negative")
+	case c.BailoutRadius < 2:
+		return errors.New("bailout must be at least 2")
+	case c.MandelWorkers < 1:
+		return errors.New("workers must be positive")
+	case c.ImageCount < 1:
+		return errors.New("count must be positive")
+	case c.StartLocation < 0 || c.StartLocation >= len(viewport.Locations):
+		return fmt.Errorf("location must be between 0 and %v", len(viewport.Locations)-1)
+	case c.ScaleRatio < 0 || c.ScaleRatio >= 1:
+		return errors.New("scale must be in [0, 1)")
+	case !(c.Radius.M > 0) || math.IsInf(c.Radius.M, 0):
+		return errors.New("radius must be positive and finite")
+	case c.Retries < 0:
+		return errors.New("retries must not be negative")
+	}
+	if err := validateOnError(c.OnError); err != nil {
+		return err
+	}
+	if err := validateIterPolicy(c.IterPolicy); err != nil {
+		return err
+	}
+	if _, err := render.StrategyByName(c.Strategy); err != nil {
+		return err
+	}
+	if err := validateFractal(c.Fractal); err != nil {
+		return err
+	}
+	formula, err := fractal.FormulaByName(c.Formula, c.Degree)
+	if err != nil {
+		return err
+	}
+	// only the quadratic kernels track dz/dc
+	if _, quadratic := formula.(fractal.Quadratic); (c.Distance || c.Boundary > 0 || c.Shading > 0) &&
+		(!quadratic || c.Fractal == fractalNewton) {
+		return errors.New("distance, boundary and shading need the mandelbrot formula, not newton or other formulas")
+	}
+	if c.Fractal == fractalNewton {
+		if _, err := fractal.ParsePolynomial(c.Polynomial); err != nil {
+			return err
+		}
+		if c.Tolerance <= 0 {
+			return errors.New("tolerance must be positive")
+		}
+	}
+	if err := c.validateColoring(); err != nil {
+		return err
+	}
+	if c.Resume && c.Histogram && c.HistSmoothing > 0 && c.Buffer == "" {
+		return errors.New("resume with histogram smoothing needs -buffer to restore the histogram of the finished frames")
+	}
+	for _, pattern := range []string{c.Buffer, c.NPY, c.Raw} {
+		if pattern == "" {
+			continue
+		}
+		if err := validateOutput(pattern); err != nil {
+			return err
+		}
+	}
+	return validateOutput(c.Output)
+}
+
+// addColoringFlags adds the flags that only change the colors, they are
+// shared with recolor.
+func addColoringFlags(fs *flag.FlagSet, cfg *RenderConfig) {
+	fs.StringVar(&cfg.Palette, "palette", cfg.Palette, "palette name, see mandelgo palettes")
+	fs.Func("palette-file", "load the palettes of a .gpl, .map, .ugr or .css file, can be repeated", cfg.loadPalette)
+	fs.StringVar(&cfg.Interpolation, "interpolation", cfg.Interpolation,
+		"color space the palette is blended in: "+strings.Join(palette.SpaceNames, ", ")+" (default the palette's own)")
+	fs.StringVar(&cfg.Easing, "easing", cfg.Easing,
+		"blend between palette colors: "+strings.Join(palette.EasingNames, ", ")+" (default the palette's own)")
+	fs.BoolVar(&cfg.Histogram, "histogram", cfg.Histogram,
+		"spread the palette by the distribution of the iteration counts in the frame")
+	fs.Float64Var(&cfg.HistSmoothing, "histogram-smoothing", cfg.HistSmoothing,
+		"weight in [0, 1) of the earlier frames in -histogram, keeps zooms from flickering")
+	fs.Float64Var(&cfg.Boundary, "boundary", cfg.Boundary, "width in pixels of lines along the boundary of the set, 0 draws none")
+	fs.StringVar(&cfg.BoundaryColor, "boundary-color", cfg.BoundaryColor, "CSS color of the -boundary lines")
+	fs.Float64Var(&cfg.Shading, "shading", cfg.Shading, "darken the colors towards the boundary of the set, from 0 to 1")
+	fs.StringVar(&cfg.Trap, "trap", cfg.Trap,
+		"color by the closest approach of the orbits to a trap: "+strings.Join(fractal.TrapNames, ", "))
+	fs.Func("trap-center", "center of the -trap like 0.5+0.5i (default 0)", func(v string) error {
+		z, err := strconv.ParseComplex(v, 128)
+		cfg.TrapCenter = z
+		return err
+	})
+	fs.Float64Var(&cfg.TrapAngle, "trap-angle", cfg.TrapAngle, "angle of a line or cross -trap in degrees")
+	fs.Float64Var(&cfg.TrapSize, "trap-size", cfg.TrapSize, "radius of a circle -trap, width of an image -trap")
+	fs.Func("trap-image", "PNG of an image -trap, transparent pixels do not trap", cfg.loadTrapImage)
+	fs.Float64Var(&cfg.TrapScale, "trap-scale", cfg.TrapScale, "trap distance of one pass through the palette")
+}
+
+// loadTrapImage reads the PNG of an image trap.
+func (c *RenderConfig) loadTrapImage(fileName string) error {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		return fmt.Errorf("%v: %w", fileName, err)
+	}
+	c.TrapImage, c.trapImage = fileName, img
+	return nil
+}
+
+// loadPalette registers the palettes of a file so -palette can name them.
+func (c *RenderConfig) loadPalette(fileName string) error {
+	if _, err := palette.Default.LoadFile(fileName); err != nil {
+		return err
+	}
+	c.PaletteFiles = append(c.PaletteFiles, fileName)
+	return nil
+}
+
+func (c *RenderConfig) validateColoring() error {
+	if _, ok := palette.Default.Lookup(c.Palette); !ok {
+		return fmt.Errorf("unknown palette %q", c.Palette)
+	}
+	if c.Interpolation != "" {
+		if _, err := palette.SpaceByName(c.Interpolation); err != nil {
+			return err
+		}
+	}
+	if c.Easing != "" {
+		if _, err := palette.EasingByName(c.Easing); err != nil {
+			return err
+		}
+	}
+	if c.HistSmoothing < 0 || c.HistSmoothing >= 1 {
+		return errors.New("histogram smoothing must be in [0, 1)")
+	}
+	if c.Boundary < 0 {
+		return errors.New("boundary must not be negative")
+	}
+	if _, err := palette.ParseColor(c.BoundaryColor); err != nil {
+		return err
+	}
+	if c.Shading < 0 || c.Shading > 1 {
+		return errors.New("shading must be in [0, 1]")
+	}
+	if c.Trap != "" {
+		if c.Fractal == fractalNewton {
+			return errors.New("newton fractals have no orbit traps")
+		}
+		if c.Trap == "image" && c.trapImage == nil {
+			return errors.New("trap image needs -trap-image")
+		}
+		if _, err := c.trap(); err != nil {
+			return err
+		}
+		if c.TrapScale <= 0 {
+			return errors.New("trap scale must be positive")
+		}
+	}
+	return nil
+}
+
+// trap returns the orbit trap, nil for none.
+func (c *RenderConfig) trap() (fractal.Trap, error) {
+	if c.Trap == "" {
+		return nil, nil
+	}
+	return fractal.TrapByName(c.Trap, c.TrapCenter, c.TrapAngle, c.TrapSize, c.trapImage)
+}
+
+func (c *RenderConfig) coloring() render.Coloring {
+	registered, _ := palette.Default.Lookup(c.Palette)
+	g := *registered
+	if c.Interpolation != "" {
+		g.Space, _ = palette.SpaceByName(c.Interpolation)
+	}
+	if c.Easing != "" {
+		g.Easing, _ = palette.EasingByName(c.Easing)
+	}
+	boundaryColor, _ := palette.ParseColor(c.BoundaryColor)
+	coloring := render.Coloring{
+		Gradient:      &g,
+		Boundary:      c.Boundary,
+		BoundaryColor: boundaryColor,
+		Shading:       c.Shading,
+		TrapScale:     c.TrapScale,
+	}
+	coloring.Trap, _ = c.trap()
+	if c.Histogram {
+		coloring.Histogram = &render.Histogram{Smoothing: c.HistSmoothing}
+	}
+	return coloring
+}
+
+func (c *RenderConfig) job(view viewport.BigRectangle) render.Job {
+	formula, _ := fractal.FormulaByName(c.Formula, c.Degree)
+	var kernel fractal.Kernel
+	if c.Fractal == fractalNewton {
+		poly, _ := fractal.ParsePolynomial(c.Polynomial)
+		kernel, _ = fractal.NewNewton(poly, c.Tolerance)
+	}
+	strategy, _ := render.StrategyByName(c.Strategy)
+	trap, _ := c.trap()
+	return render.Job{
+		BigView:       &view,
+		Width:         c.ImageWidth,
+		Height:        c.ImageHeight,
+		MaxIter:       c.MaxIter,
+		Periodicity:   c.Periodicity,
+		Strategy:      strategy,
+		Distance:      c.Distance || c.Boundary > 0 || c.Shading > 0,
+		BailoutRadius: c.BailoutRadius,
+		Workers:       c.MandelWorkers,
+		Gradient:      c.coloring().Gradient,
+		Formula:       formula,
+		Julia:         c.Fractal == fractalJulia,
+		C:             c.C,
+		Kernel:        kernel,
+		Trap:          trap,
+	}
+}
+
+// iterationPolicy returns the policy for MaxIter, it starts at MaxIter.
+func (c *RenderConfig) iterationPolicy() render.IterationPolicy {
+	switch c.IterPolicy {
+	case iterDepth:
+		return render.Depth{Min: c.MaxIter, Max: c.MaxIterLimit, Base: c.DepthBase, Exponent: c.DepthExponent}
+	case iterAdaptive:
+		return render.Adaptive{Min: c.MaxIter, Max: c.MaxIterLimit, Target: c.AdaptTarget, Growth: c.AdaptGrowth}
+	}
+	return render.Fixed(c.MaxIter)
+}
+
+func validateIterPolicy(name string) error {
+	switch name {
+	case iterFixed, iterDepth, iterAdaptive:
+		return nil
+	}
+	return fmt.Errorf("iterations must be fixed, depth or adaptive, not %q", name)
+}
+
+func validateFractal(name string) error {
+	switch name {
+	case fractalMandelbrot, fractalJulia, fractalNewton:
+		return nil
+	}
+	return fmt.Errorf("unknown fractal %q", name)
+}
+
+func validateTrap(name string) error {
+	for _, n := range fractal.TrapNames {
+		if n == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown trap %q", name)
+}
+
+// locationCenter returns the center of the location with the given index.
+func locationCenter(index string) (complex128, error) {
+	i, err := strconv.Atoi(index)
+	if err != nil || i < 0 || i >= len(viewport.Locations) {
+		return 0, fmt.Errorf("location must be between 0 and %v", len(viewport.Locations)-1)
+	}
+	return complex(viewport.Locations[i].X, viewport.Locations[i].Y), nil
+}
+
+func validateOnError(policy string) error {
+	switch policy {
+	case onErrorAbort, onErrorRetry, onErrorSkip:
+		return nil
+	}
+	return fmt.Errorf("on-error must be abort, retry or skip, not %q", policy)
+}
+
+func validateOutput(pattern string) error {
+	if strings.Count(pattern, "%")-2*strings.Count(pattern, "%%") != 1 {
+		return fmt.Errorf("output %q needs exactly one verb for the image number", pattern)
+	}
+	return nil
+}
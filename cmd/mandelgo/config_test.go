@@ -0,0 +1,28 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jfhaecker/mandelgo/floatexp"
+)
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		set  func(c *RenderConfig)
+		want string
+	}{
+		{"radius", func(c *RenderConfig) { c.Radius.M = 0 }, "radius"},
+		{"distance", func(c *RenderConfig) { c.Distance, c.Formula = true, "burningship" }, "distance"},
+		{"boundary", func(c *RenderConfig) { c.Boundary, c.Formula, c.Degree = 1, "multibrot", 3 }, "distance"},
+		{"shading", func(c *RenderConfig) { c.Shading, c.Fractal, c.Polynomial = 0.5, fractalNewton, "z^3-1" }, "distance"},
+		{"infinite radius", func(c *RenderConfig) { c.Radius, _ = floatexp.ParseFloat("inf") }, "radius"},
+	} {
+		cfg := newRenderConfig()
+		tc.set(cfg)
+		if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%v: error %v, want %q", tc.name, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,787 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jfhaecker/mandelgo/floatexp"
+	"github.com/jfhaecker/mandelgo/fractal"
+	"github.com/jfhaecker/mandelgo/palette"
+	"github.com/jfhaecker/mandelgo/render"
+	"github.com/jfhaecker/mandelgo/viewport"
+)
+
+// A job file is a render recipe that can be checked into git. YAML, TOML and
+// JSON are understood as long as they stick to one level of sections:
+//
+//	location:
+//	  index: 18        # or x, y
+//	  radius: 0.05
+//	image:
+//	  width: 1000
+//	  height: 1000
+//	  strategy: tiles  # subdivide or subdivide-interior
+//	iterations:
+//	  max: 1000
+//	  policy: fixed    # depth or adaptive, start at max
+//	  limit: 100000
+//	  depth_base: 50         # depth: base*log10(zoom)^exponent
+//	  depth_exponent: 1.25
+//	  adaptive_target: 0.1   # adaptive: grow once this fraction reaches max
+//	  adaptive_growth: 1.25
+//	  periodicity: 1e-9  # in pixels, 0 disables cycle detection
+//	  distance: false  # estimate the distance to the set
+//	  bailout: 20
+//	palette: quake
+//	palette_file: fire.gpl, sky.css  # registered before palette is looked up
+//	coloring:
+//	  interpolation: oklab  # srgb, linear, lch or hsv, default the palette's own
+//	  easing: ease-in-out   # linear, ease-in or ease-out
+//	  histogram: true       # spread the palette by the iteration distribution
+//	  histogram_smoothing: 0.8  # weight of the earlier frames
+//	  boundary: 1           # lines along the set, in pixels
+//	  boundary_color: black
+//	  shading: 0.5          # darken towards the set, 0 to 1
+//	trap:
+//	  type: circle     # point, line, cross or image
+//	  center: 0+0i
+//	  angle: 0         # degrees, line and cross
+//	  size: 1          # circle radius, image width
+//	  image: logo.png  # image only
+//	  scale: 1         # trap distance of one pass through the palette
+//	zoom:
+//	  ratio: 0.03
+//	  frames: 650
+//	output: mandel-%03v.png
+//	buffer: mandel-%03v.mgb  # iteration data for recolor
+//	export:
+//	  npy: mandel-%03v.npy
+//	  raw: mandel-%03v.raw     # with a mandel-%03v.raw.json sidecar
+//	errors:
+//	  policy: abort    # retry or skip
+//	  retries: 3
+//	fractal:
+//	  type: julia      # or mandelbrot
+//	  c: -0.8+0.156i   # or c_location: 18
+//	  formula: multibrot
+//	  degree: 3
+//	  polynomial: z^3-1  # newton only
+//	  tolerance: 1e-6
+
+type jobEntry struct {
+	key   string
+	value string
+	line  int
+}
+
+type JobError struct {
+	File string
+	Line int
+	Err  error
+}
+
+func (e *JobError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%v: %v", e.File, e.Err)
+	}
+	return fmt.Sprintf("%v:%v: %v", e.File, e.Line, e.Err)
+}
+
+type jobField struct {
+	key string
+	set func(cfg *RenderConfig, value string) error
+}
+
+var jobSchema = []jobField{
+	{"location.index", func(c *RenderConfig, v string) error {
+		if err := setInt(&c.StartLocation, v, 0, len(viewport.Locations)-1); err != nil {
+			return err
+		}
+		c.Center = viewport.Locations[c.StartLocation].Center()
+		return nil
+	}},
+	{"location.x", func(c *RenderConfig, v string) error {
+		x, err := viewport.ParseBigFloat(v)
+		c.Center.X = x
+		return err
+	}},
+	{"location.y", func(c *RenderConfig, v string) error {
+		y, err := viewport.ParseBigFloat(v)
+		c.Center.Y = y
+		return err
+	}},
+	{"location.radius", func(c *RenderConfig, v string) error {
+		r, err := floatexp.ParseFloat(v)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", v)
+		}
+		if !(r.M > 0) || math.IsInf(r.M, 0) {
+			return errors.New("must be greater than 0 and finite")
+		}
+		c.Radius = r
+		return nil
+	}},
+	{"image.width", intField(func(c *RenderConfig) *int { return &c.ImageWidth }, 2)},
+	{"image.height", intField(func(c *RenderConfig) *int { return &c.ImageHeight }, 2)},
+	{"image.strategy", func(c *RenderConfig, v string) error {
+		if _, err := render.StrategyByName(v); err != nil {
+			return err
+		}
+		c.Strategy = v
+		return nil
+	}},
+	{"iterations.max", intField(func(c *RenderConfig) *int { return &c.MaxIter }, 1)},
+	{"iterations.policy", func(c *RenderConfig, v string) error {
+		if err := validateIterPolicy(v); err != nil {
+			return err
+		}
+		c.IterPolicy = v
+		return nil
+	}},
+	{"iterations.limit", intField(func(c *RenderConfig) *int { return &c.MaxIterLimit }, 0)},
+	{"iterations.depth_base", floatField(func(c *RenderConfig) *float64 { return &c.DepthBase }, 0, false)},
+	{"iterations.depth_exponent", floatField(func(c *RenderConfig) *float64 { return &c.DepthExponent }, 0, false)},
+	{"iterations.adaptive_target", func(c *RenderConfig, v string) error {
+		f, err := parseFloat(v)
+		if err != nil {
+			return err
+		}
+		if f < 0 || f >= 1 {
+			return errors.New("must be in [0, 1)")
+		}
+		c.AdaptTarget = f
+		return nil
+	}},
+	{"iterations.adaptive_growth", floatField(func(c *RenderConfig) *float64 { return &c.AdaptGrowth }, 1, false)},
+	{"iterations.periodicity", floatField(func(c *RenderConfig) *float64 { return &c.Periodicity }, 0, true)},
+	{"iterations.distance", boolField(func(c *RenderConfig) *bool { return &c.Distance })},
+	{"iterations.bailout", floatField(func(c *RenderConfig) *float64 { return &c.BailoutRadius }, 2, true)},
+	{"palette", func(c *RenderConfig, v string) error {
+		if _, ok := palette.Default.Lookup(v); !ok {
+			return fmt.Errorf("unknown palette %q", v)
+		}
+		c.Palette = v
+		return nil
+	}},
+	{"palette_file", func(c *RenderConfig, v string) error {
+		for _, f := range splitList(v) {
+			if err := c.loadPalette(f); err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+	{"coloring.interpolation", func(c *RenderConfig, v string) error {
+		if _, err := palette.SpaceByName(v); err != nil {
+			return err
+		}
+		c.Interpolation = v
+		return nil
+	}},
+	{"coloring.easing", func(c *RenderConfig, v string) error {
+		if _, err := palette.EasingByName(v); err != nil {
+			return err
+		}
+		c.Easing = v
+		return nil
+	}},
+	{"coloring.histogram", boolField(func(c *RenderConfig) *bool { return &c.Histogram })},
+	{"coloring.histogram_smoothing", func(c *RenderConfig, v string) error {
+		f, err := parseFloat(v)
+		if err != nil {
+			return err
+		}
+		if f < 0 || f >= 1 {
+			return errors.New("must be in [0, 1)")
+		}
+		c.HistSmoothing = f
+		return nil
+	}},
+	{"coloring.boundary", floatField(func(c *RenderConfig) *float64 { return &c.Boundary }, 0, true)},
+	{"coloring.boundary_color", func(c *RenderConfig, v string) error {
+		if _, err := palette.ParseColor(v); err != nil {
+			return err
+		}
+		c.BoundaryColor = v
+		return nil
+	}},
+	{"coloring.shading", func(c *RenderConfig, v string) error {
+		f, err := parseFloat(v)
+		if err != nil {
+			return err
+		}
+		if f < 0 || f > 1 {
+			return errors.New("must be in [0, 1]")
+		}
+		c.Shading = f
+		return nil
+	}},
+	{"trap.type", func(c *RenderConfig, v string) error {
+		if err := validateTrap(v); err != nil {
+			return err
+		}
+		c.Trap = v
+		return nil
+	}},
+	{"trap.center", func(c *RenderConfig, v string) error {
+		z, err := strconv.ParseComplex(v, 128)
+		if err != nil {
+			return fmt.Errorf("%q is not a complex number", v)
+		}
+		c.TrapCenter = z
+		return nil
+	}},
+	{"trap.angle", func(c *RenderConfig, v string) error {
+		f, err := parseFloat(v)
+		c.TrapAngle = f
+		return err
+	}},
+	{"trap.size", floatField(func(c *RenderConfig) *float64 { return &c.TrapSize }, 0, true)},
+	{"trap.image", func(c *RenderConfig, v string) error { return c.loadTrapImage(v) }},
+	{"trap.scale", floatField(func(c *RenderConfig) *float64 { return &c.TrapScale }, 0, false)},
+	{"zoom.ratio", func(c *RenderConfig, v string) error {
+		f, err := parseFloat(v)
+		if err != nil {
+			return err
+		}
+		if f < 0 || f >= 1 {
+			return errors.New("must be in [0, 1)")
+		}
+		c.ScaleRatio = f
+		return nil
+	}},
+	{"zoom.frames", intField(func(c *RenderConfig) *int { return &c.ImageCount }, 1)},
+	{"output", func(c *RenderConfig, v string) error {
+		if err := validateOutput(v); err != nil {
+			return err
+		}
+		c.Output = v
+		return nil
+	}},
+	{"buffer", func(c *RenderConfig, v string) error {
+		if err := validateOutput(v); err != nil {
+			return err
+		}
+		c.Buffer = v
+		return nil
+	}},
+	{"export.npy", func(c *RenderConfig, v string) error {
+		if err := validateOutput(v); err != nil {
+			return err
+		}
+		c.NPY = v
+		return nil
+	}},
+	{"export.raw", func(c *RenderConfig, v string) error {
+		if err := validateOutput(v); err != nil {
+			return err
+		}
+		c.Raw = v
+		return nil
+	}},
+	{"errors.policy", func(c *RenderConfig, v string) error {
+		if err := validateOnError(v); err != nil {
+			return err
+		}
+		c.OnError = v
+		return nil
+	}},
+	{"errors.retries", intField(func(c *RenderConfig) *int { return &c.Retries }, 0)},
+	{"fractal.type", func(c *RenderConfig, v string) error {
+		if err := validateFractal(v); err != nil {
+			return err
+		}
+		c.Fractal = v
+		return nil
+	}},
+	{"fractal.c", func(c *RenderConfig, v string) error {
+		z, err := strconv.ParseComplex(v, 128)
+		if err != nil {
+			return fmt.Errorf("%q is not a complex number", v)
+		}
+		c.C = z
+		return nil
+	}},
+	{"fractal.formula", func(c *RenderConfig, v string) error {
+		if _, err := fractal.FormulaByName(v, 2); err != nil {
+			return err
+		}
+		c.Formula = v
+		return nil
+	}},
+	{"fractal.degree", floatField(func(c *RenderConfig) *float64 { return &c.Degree }, 1, false)},
+	{"fractal.polynomial", func(c *RenderConfig, v string) error {
+		if _, err := fractal.ParsePolynomial(v); err != nil {
+			return err
+		}
+		c.Polynomial = v
+		return nil
+	}},
+	{"fractal.tolerance", floatField(func(c *RenderConfig) *float64 { return &c.Tolerance }, 0, false)},
+	{"fractal.c_location", func(c *RenderConfig, v string) error {
+		z, err := locationCenter(v)
+		c.C = z
+		return err
+	}},
+}
+
+func intField(field func(*RenderConfig) *int, min int) func(*RenderConfig, string) error {
+	return func(c *RenderConfig, v string) error {
+		return setInt(field(c), v, min, -1)
+	}
+}
+
+func setInt(dst *int, v string, min, max int) error {
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("%q is not an integer", v)
+	}
+	if i < min || (max >= min && i > max) {
+		if max >= min {
+			return fmt.Errorf("must be between %v and %v", min, max)
+		}
+		return fmt.Errorf("must be at least %v", min)
+	}
+	*dst = i
+	return nil
+}
+
+func floatField(field func(*RenderConfig) *float64, min float64, inclusive bool) func(*RenderConfig, string) error {
+	return func(c *RenderConfig, v string) error {
+		f, err := parseFloat(v)
+		if err != nil {
+			return err
+		}
+		if f < min || (!inclusive && f == min) {
+			if inclusive {
+				return fmt.Errorf("must be at least %v", min)
+			}
+			return fmt.Errorf("must be greater than %v", min)
+		}
+		*field(c) = f
+		return nil
+	}
+}
+
+func boolField(field func(*RenderConfig) *bool) func(*RenderConfig, string) error {
+	return func(c *RenderConfig, v string) error {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", v)
+		}
+		*field(c) = b
+		return nil
+	}
+}
+
+func parseFloat(v string) (float64, error) {
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", v)
+	}
+	return f, nil
+}
+
+// loadJob reads and validates a job file on top of the default config.
+func loadJob(fileName string) (*RenderConfig, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var entries []jobEntry
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".yaml", ".yml":
+		entries, err = parseYAMLJob(data)
+	case ".toml":
+		entries, err = parseTOMLJob(data)
+	case ".json":
+		entries, err = parseJSONJob(data)
+	default:
+		err = errors.New("unknown job format, use .yaml, .toml or .json")
+	}
+	if err != nil {
+		if jerr, ok := err.(*JobError); ok {
+			jerr.File = fileName
+			return nil, jerr
+		}
+		return nil, &JobError{File: fileName, Err: err}
+	}
+	if len(entries) == 0 {
+		return nil, &JobError{File: fileName, Err: errors.New("the job sets nothing")}
+	}
+
+	// palette files first, palette can name what they register
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].key == "palette_file" && entries[j].key != "palette_file"
+	})
+	cfg := newRenderConfig()
+	seen := map[string]int{}
+	for _, e := range entries {
+		field := lookupJobField(e.key)
+		if field == nil {
+			return nil, &JobError{fileName, e.line, fmt.Errorf("unknown key %q", e.key)}
+		}
+		if line, ok := seen[e.key]; ok {
+			return nil, &JobError{fileName, e.line, fmt.Errorf("%v already set on line %v", e.key, line)}
+		}
+		seen[e.key] = e.line
+		value := e.value
+		if e.key != "palette_file" {
+			value = unquote(value)
+		}
+		if err := field.set(cfg, value); err != nil {
+			return nil, &JobError{fileName, e.line, fmt.Errorf("%v: %v", e.key, err)}
+		}
+	}
+	if line, ok := seen["fractal.c_location"]; ok {
+		if _, c := seen["fractal.c"]; c {
+			return nil, &JobError{fileName, line, errors.New("fractal.c_location conflicts with fractal.c")}
+		}
+	}
+	for _, pair := range [][2]string{{"location.x", "location.y"}, {"location.y", "location.x"}} {
+		if line, ok := seen[pair[0]]; ok {
+			if _, other := seen[pair[1]]; !other {
+				return nil, &JobError{fileName, line, fmt.Errorf("%v needs %v", pair[0], pair[1])}
+			}
+		}
+	}
+	if line, ok := seen["location.index"]; ok {
+		if _, x := seen["location.x"]; x {
+			return nil, &JobError{fileName, line, errors.New("location.index conflicts with location.x")}
+		}
+		if _, y := seen["location.y"]; y {
+			return nil, &JobError{fileName, line, errors.New("location.index conflicts with location.y")}
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, &JobError{File: fileName, Err: err}
+	}
+	return cfg, nil
+}
+
+func lookupJobField(key string) *jobField {
+	for i := range jobSchema {
+		if jobSchema[i].key == key {
+			return &jobSchema[i]
+		}
+	}
+	return nil
+}
+
+// stripComment cuts off a # comment outside of quotes. Like in YAML the #
+// has to start the line or follow whitespace, so values like f#%v.png stay
+// whole.
+func stripComment(line string) string {
+	quote := byte(0)
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// unquote removes the quotes around a value, double quoted values can use
+// the escapes of Go strings like writeJob's %q.
+func unquote(v string) string {
+	if len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"' {
+		if s, err := strconv.Unquote(v); err == nil {
+			return s
+		}
+	}
+	if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+		return v[1 : len(v)-1]
+	}
+	return v
+}
+
+// splitList splits a comma separated list, commas inside quotes belong to
+// the quoted item.
+func splitList(v string) []string {
+	var items []string
+	quote, start := byte(0), 0
+	for i := 0; i < len(v); i++ {
+		switch c := v[i]; {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			items = append(items, unquote(strings.TrimSpace(v[start:i])))
+			start = i + 1
+		}
+	}
+	return append(items, unquote(strings.TrimSpace(v[start:])))
+}
+
+func parseYAMLJob(data []byte) ([]jobEntry, error) {
+	var entries []jobEntry
+	section := ""
+	for i, raw := range strings.Split(string(data), "\n") {
+		line := i + 1
+		text := strings.TrimRight(stripComment(raw), " \t\r")
+		if strings.TrimSpace(text) == "" || text == "---" {
+			continue
+		}
+		if strings.HasPrefix(text, "\t") {
+			return nil, &JobError{Line: line, Err: errors.New("tabs are not allowed for indentation")}
+		}
+		trimmed := strings.TrimLeft(text, " ")
+		indented := len(trimmed) < len(text)
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			return nil, &JobError{Line: line, Err: fmt.Errorf("expected key: value, got %q", trimmed)}
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		switch {
+		case !indented && value == "":
+			section = key
+		case !indented:
+			section = ""
+			entries = append(entries, jobEntry{key, value, line})
+		case section == "":
+			return nil, &JobError{Line: line, Err: fmt.Errorf("unexpected indentation of %q", key)}
+		case value == "":
+			return nil, &JobError{Line: line, Err: fmt.Errorf("%v.%v: nesting is limited to one level", section, key)}
+		default:
+			entries = append(entries, jobEntry{section + "." + key, value, line})
+		}
+	}
+	return entries, nil
+}
+
+func parseTOMLJob(data []byte) ([]jobEntry, error) {
+	var entries []jobEntry
+	section := ""
+	for i, raw := range strings.Split(string(data), "\n") {
+		line := i + 1
+		text := strings.TrimSpace(stripComment(raw))
+		if text == "" {
+			continue
+		}
+		if strings.HasPrefix(text, "[") {
+			if !strings.HasSuffix(text, "]") {
+				return nil, &JobError{Line: line, Err: fmt.Errorf("malformed section %q", text)}
+			}
+			section = strings.TrimSpace(text[1 : len(text)-1])
+			continue
+		}
+		key, value, ok := strings.Cut(text, "=")
+		if !ok {
+			return nil, &JobError{Line: line, Err: fmt.Errorf("expected key = value, got %q", text)}
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if section != "" {
+			key = section + "." + key
+		}
+		entries = append(entries, jobEntry{key, value, line})
+	}
+	return entries, nil
+}
+
+func parseJSONJob(data []byte) ([]jobEntry, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	lineAt := func(offset int64) int {
+		return bytes.Count(data[:offset], []byte("\n")) + 1
+	}
+	fail := func(err error) error {
+		if serr, ok := err.(*json.SyntaxError); ok {
+			return &JobError{Line: lineAt(serr.Offset), Err: err}
+		}
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return &JobError{Line: lineAt(dec.InputOffset()), Err: err}
+	}
+
+	var entries []jobEntry
+	var object func(prefix string) error
+	object = func(prefix string) error {
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return fail(err)
+			}
+			key := prefix + tok.(string)
+			line := lineAt(dec.InputOffset())
+			tok, err = dec.Token()
+			if err != nil {
+				return fail(err)
+			}
+			switch v := tok.(type) {
+			case json.Delim:
+				if v != '{' || prefix != "" {
+					return &JobError{Line: line, Err: fmt.Errorf("%v: expected a value or one level of object", key)}
+				}
+				if err := object(key + "."); err != nil {
+					return err
+				}
+			case json.Number:
+				entries = append(entries, jobEntry{key, v.String(), line})
+			case string:
+				entries = append(entries, jobEntry{key, v, line})
+			case bool:
+				entries = append(entries, jobEntry{key, strconv.FormatBool(v), line})
+			default:
+				return &JobError{Line: line, Err: fmt.Errorf("%v: null is not a value, leave the key out", key)}
+			}
+		}
+		_, err := dec.Token() // closing }
+		if err != nil {
+			return fail(err)
+		}
+		return nil
+	}
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, fail(err)
+	}
+	if tok != json.Delim('{') {
+		return nil, &JobError{Line: 1, Err: errors.New("job must be a JSON object")}
+	}
+	if err := object(""); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// writeJob prints the resolved job as YAML so a run can be reproduced.
+func writeJob(w io.Writer, cfg *RenderConfig) {
+	ff := func(f float64) string { return strconv.FormatFloat(f, 'g', -1, 64) }
+	fmt.Fprintf(w, "location:\n  x: %v\n  y: %v\n  radius: %v\n",
+		cfg.Center.X.Text('g', -1), cfg.Center.Y.Text('g', -1), cfg.Radius)
+	fmt.Fprintf(w, "image:\n  width: %v\n  height: %v\n  strategy: %v\n", cfg.ImageWidth, cfg.ImageHeight, cfg.Strategy)
+	fmt.Fprintf(w, "iterations:\n  max: %v\n  policy: %v\n", cfg.MaxIter, cfg.IterPolicy)
+	if cfg.IterPolicy != iterFixed {
+		fmt.Fprintf(w, "  limit: %v\n", cfg.MaxIterLimit)
+	}
+	switch cfg.IterPolicy {
+	case iterDepth:
+		fmt.Fprintf(w, "  depth_base: %v\n  depth_exponent: %v\n", ff(cfg.DepthBase), ff(cfg.DepthExponent))
+	case iterAdaptive:
+		fmt.Fprintf(w, "  adaptive_target: %v\n  adaptive_growth: %v\n", ff(cfg.AdaptTarget), ff(cfg.AdaptGrowth))
+	}
+	fmt.Fprintf(w, "  periodicity: %v\n", ff(cfg.Periodicity))
+	if cfg.Distance {
+		fmt.Fprintf(w, "  distance: true\n")
+	}
+	fmt.Fprintf(w, "  bailout: %v\n", ff(cfg.BailoutRadius))
+	fmt.Fprintf(w, "palette: %q\n", cfg.Palette)
+	if len(cfg.PaletteFiles) > 0 {
+		quoted := make([]string, len(cfg.PaletteFiles))
+		for i, f := range cfg.PaletteFiles {
+			quoted[i] = strconv.Quote(f)
+		}
+		fmt.Fprintf(w, "palette_file: %v\n", strings.Join(quoted, ", "))
+	}
+	if cfg.Interpolation != "" || cfg.Easing != "" || cfg.Histogram || cfg.Boundary > 0 || cfg.Shading > 0 {
+		fmt.Fprintf(w, "coloring:\n")
+		if cfg.Interpolation != "" {
+			fmt.Fprintf(w, "  interpolation: %v\n", cfg.Interpolation)
+		}
+		if cfg.Easing != "" {
+			fmt.Fprintf(w, "  easing: %v\n", cfg.Easing)
+		}
+		if cfg.Histogram {
+			fmt.Fprintf(w, "  histogram: true\n  histogram_smoothing: %v\n", ff(cfg.HistSmoothing))
+		}
+		if cfg.Boundary > 0 {
+			fmt.Fprintf(w, "  boundary: %v\n  boundary_color: %q\n", ff(cfg.Boundary), cfg.BoundaryColor)
+		}
+		if cfg.Shading > 0 {
+			fmt.Fprintf(w, "  shading: %v\n", ff(cfg.Shading))
+		}
+	}
+	if cfg.Trap != "" {
+		fmt.Fprintf(w, "trap:\n  type: %v\n  center: %v\n", cfg.Trap, strconv.FormatComplex(cfg.TrapCenter, 'g', -1, 128))
+		switch cfg.Trap {
+		case "line", "cross":
+			fmt.Fprintf(w, "  angle: %v\n", ff(cfg.TrapAngle))
+		case "circle":
+			fmt.Fprintf(w, "  size: %v\n", ff(cfg.TrapSize))
+		case "image":
+			fmt.Fprintf(w, "  size: %v\n  image: %q\n", ff(cfg.TrapSize), cfg.TrapImage)
+		}
+		fmt.Fprintf(w, "  scale: %v\n", ff(cfg.TrapScale))
+	}
+	fmt.Fprintf(w, "zoom:\n  ratio: %v\n  frames: %v\n", ff(cfg.ScaleRatio), cfg.ImageCount)
+	fmt.Fprintf(w, "output: %q\n", cfg.Output)
+	if cfg.Buffer != "" {
+		fmt.Fprintf(w, "buffer: %q\n", cfg.Buffer)
+	}
+	if cfg.NPY != "" || cfg.Raw != "" {
+		fmt.Fprintf(w, "export:\n")
+		if cfg.NPY != "" {
+			fmt.Fprintf(w, "  npy: %q\n", cfg.NPY)
+		}
+		if cfg.Raw != "" {
+			fmt.Fprintf(w, "  raw: %q\n", cfg.Raw)
+		}
+	}
+	fmt.Fprintf(w, "errors:\n  policy: %v\n  retries: %v\n", cfg.OnError, cfg.Retries)
+	fmt.Fprintf(w, "fractal:\n  type: %v\n", cfg.Fractal)
+	switch cfg.Fractal {
+	case fractalJulia:
+		fmt.Fprintf(w, "  c: %v\n", strconv.FormatComplex(cfg.C, 'g', -1, 128))
+	case fractalNewton:
+		fmt.Fprintf(w, "  polynomial: %q\n  tolerance: %v\n", cfg.Polynomial, strconv.FormatFloat(cfg.Tolerance, 'g', -1, 64))
+	}
+	if cfg.Fractal != fractalNewton {
+		fmt.Fprintf(w, "  formula: %v\n", cfg.Formula)
+		if cfg.Formula == "multibrot" {
+			fmt.Fprintf(w, "  degree: %v\n", strconv.FormatFloat(cfg.Degree, 'g', -1, 64))
+		}
+	}
+}
+
+// parseRender handles `mandelgo render [-workers n] [-resume] job-file`.
+func parseRender(args []string, output io.Writer) (*RenderConfig, error) {
+	fs := flag.NewFlagSet("mandelgo render", flag.ContinueOnError)
+	fs.SetOutput(output)
+	workers := fs.Int("workers", newRenderConfig().MandelWorkers, "number of mandel workers")
+	resume := fs.Bool("resume", false, "skip images that are already rendered")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: mandelgo render [-workers n] [-resume] job.(yaml|json|toml)\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return nil, errors.New("render needs exactly one job file")
+	}
+	cfg, err := loadJob(fs.Arg(0))
+	if err != nil {
+		return nil, err
+	}
+	cfg.MandelWorkers = *workers
+	cfg.Resume = *resume
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
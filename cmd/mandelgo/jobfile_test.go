@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStripComment(t *testing.T) {
+	for _, tc := range []struct{ line, want string }{
+		{"# comment", ""},
+		{"max: 100  # comment", "max: 100  "},
+		{"max: 100\t# comment", "max: 100\t"},
+		{"output: f#%v.png", "output: f#%v.png"},
+		{"output: f#%v.png # comment", "output: f#%v.png "},
+		{`color: "#ff0000" # red`, `color: "#ff0000" `},
+		{"color: '# not a comment'", "color: '# not a comment'"},
+	} {
+		if got := stripComment(tc.line); got != tc.want {
+			t.Errorf("stripComment(%q) = %q, want %q", tc.line, got, tc.want)
+		}
+	}
+}
+
+// The same job in all three formats gives the same entries.
+func TestParseJob(t *testing.T) {
+	yaml := `# deep zoom
+location:
+  index: 18  # seahorse
+  radius: 0.05
+iterations:
+  distance: true
+palette: "quake"
+output: f#%03v.png
+`
+	toml := `# deep zoom
+palette = "quake"
+output = 'f#%03v.png'
+[location]
+index = 18  # seahorse
+radius = 0.05
+[iterations]
+distance = true
+`
+	json := `{
+  "location": {
+    "index": 18,
+    "radius": 0.05
+  },
+  "iterations": {"distance": true},
+  "palette": "quake",
+  "output": "f#%03v.png"
+}`
+	want := map[string]string{
+		"location.index":      "18",
+		"location.radius":     "0.05",
+		"iterations.distance": "true",
+		"palette":             "quake",
+		"output":              "f#%03v.png",
+	}
+	for _, tc := range []struct {
+		format string
+		parse  func([]byte) ([]jobEntry, error)
+		data   string
+		line   int // of location.index
+	}{
+		{"yaml", parseYAMLJob, yaml, 3},
+		{"toml", parseTOMLJob, toml, 5},
+		{"json", parseJSONJob, json, 3},
+	} {
+		entries, err := tc.parse([]byte(tc.data))
+		if err != nil {
+			t.Errorf("%v: %v", tc.format, err)
+			continue
+		}
+		got := map[string]string{}
+		for _, e := range entries {
+			got[e.key] = unquote(e.value)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%v: got %v, want %v", tc.format, got, want)
+		}
+		for _, e := range entries {
+			if e.key == "location.index" && e.line != tc.line {
+				t.Errorf("%v: location.index on line %v, want %v", tc.format, e.line, tc.line)
+			}
+		}
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	for _, tc := range []struct {
+		v    string
+		want []string
+	}{
+		{"a.gpl", []string{"a.gpl"}},
+		{"a.gpl, b.gpl", []string{"a.gpl", "b.gpl"}},
+		{`"a,b.gpl", 'c, d.gpl',e.gpl`, []string{"a,b.gpl", "c, d.gpl", "e.gpl"}},
+		{`"say \"hi\", bye.gpl"`, []string{`say "hi", bye.gpl`}},
+	} {
+		if got := splitList(tc.v); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("splitList(%q) = %q, want %q", tc.v, got, tc.want)
+		}
+	}
+}
+
+func TestJobErrors(t *testing.T) {
+	for _, tc := range []struct{ name, data, want string }{
+		{"empty.yaml", "# nothing\n", "sets nothing"},
+		{"empty.json", "{}", "sets nothing"},
+		{"null.json", `{"image": {"width": null}}`, "null"},
+		{"tabs.yaml", "image:\n\twidth: 10\n", ":2: tabs"},
+		{"nested.yaml", "image:\n  size:\n    width: 10\n", ":2: image.size: nesting"},
+		{"unknown.toml", "[image]\ncolor = 1\n", `:2: unknown key "image.color"`},
+		{"twice.yaml", "palette: quake\npalette: quake\n", ":2: palette already set on line 1"},
+		{"x.yaml", "location:\n  radius: 1\n  x: -0.5\n", ":3: location.x needs location.y"},
+		{"y.toml", "[location]\ny = 0.1\n", ":2: location.y needs location.x"},
+		{"radius.yaml", "location:\n  index: 3\n  radius: -inf\n", ":3: location.radius: must be greater than 0 and finite"},
+		{"range.json", "{\n\"image\": {\"width\": 1}}", ":2: image.width: must be at least 2"},
+	} {
+		fileName := filepath.Join(t.TempDir(), tc.name)
+		if err := os.WriteFile(fileName, []byte(tc.data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := loadJob(fileName)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%v: error %v, want %q", tc.name, err, tc.want)
+		}
+	}
+}
+
+// The printed job loads back into the same job.
+func TestWriteJob(t *testing.T) {
+	adaptive := newRenderConfig()
+	adaptive.IterPolicy, adaptive.Distance, adaptive.Boundary = "adaptive", true, 1
+	newton := newRenderConfig()
+	newton.Fractal, newton.Polynomial, newton.Tolerance = fractalNewton, "z^4 - 1", 1e-8
+	files := newRenderConfig()
+	dir := t.TempDir()
+	for i, name := range []string{"warm, dark.gpl", `"cold".gpl`} {
+		fileName := filepath.Join(dir, name)
+		gpl := fmt.Sprintf("GIMP Palette\nName: Job Test %v\n255 0 0\n0 0 255\n", i)
+		if err := os.WriteFile(fileName, []byte(gpl), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := files.loadPalette(fileName); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files.Palette = "Job Test 1"
+	for _, cfg := range []*RenderConfig{adaptive, newton, files} {
+		var b bytes.Buffer
+		writeJob(&b, cfg)
+		fileName := filepath.Join(t.TempDir(), "job.yaml")
+		if err := os.WriteFile(fileName, b.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := loadJob(fileName)
+		if err != nil {
+			t.Fatalf("%v\n%s", err, b.Bytes())
+		}
+		var again bytes.Buffer
+		writeJob(&again, loaded)
+		if again.String() != b.String() {
+			t.Errorf("job changed on the way through a file:\n%s\nwant\n%s", again.Bytes(), b.Bytes())
+		}
+		if !reflect.DeepEqual(loaded.PaletteFiles, cfg.PaletteFiles) || loaded.Palette != cfg.Palette {
+			t.Errorf("palette %q from %q, want %q from %q", loaded.Palette, loaded.PaletteFiles, cfg.Palette, cfg.PaletteFiles)
+		}
+		if loaded.Fractal != cfg.Fractal || loaded.Polynomial != cfg.Polynomial || loaded.Tolerance != cfg.Tolerance {
+			t.Errorf("fractal %v %q %v, want %v %q %v", loaded.Fractal, loaded.Polynomial, loaded.Tolerance,
+				cfg.Fractal, cfg.Polynomial, cfg.Tolerance)
+		}
+	}
+}
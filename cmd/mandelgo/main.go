@@ -0,0 +1,215 @@
+// Command mandelgo renders a zoom flight into the Mandelbrot set.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jfhaecker/mandelgo/floatexp"
+	"github.com/jfhaecker/mandelgo/render"
+	"github.com/jfhaecker/mandelgo/viewport"
+)
+
+func main() {
+	var cfg *RenderConfig
+	var err error
+	if len(os.Args) > 1 && os.Args[1] == "recolor" {
+		os.Exit(recolor(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "palettes" {
+		os.Exit(listPalettes(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "render" {
+		cfg, err = parseRender(os.Args[2:], os.Stderr)
+		if err == nil {
+			writeJob(os.Stdout, cfg)
+		}
+	} else {
+		cfg, err = parseFlags(os.Args[1:], os.Stderr)
+	}
+	if err == flag.ErrHelp {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	fmt.Println("der haex kann das mandeln nicht lassen...")
+	fmt.Printf("Using %v mandelworkers  for %v images\n", cfg.MandelWorkers, cfg.ImageCount)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop() // a second Ctrl-C kills immediately
+	}()
+
+	os.Exit(run(ctx, cfg))
+}
+
+func printSummary(cfg *RenderConfig, completed []int, skipped []string) {
+	fmt.Printf("%v of %v images completed", len(completed), cfg.ImageCount)
+	if len(completed) > 0 {
+		fmt.Printf(": %v", frameRanges(completed))
+	}
+	fmt.Println()
+	if len(skipped) > 0 {
+		fmt.Printf("skipped: %v\n", strings.Join(skipped, " "))
+	}
+	fmt.Println("continue with --resume")
+}
+
+// frameRanges lists ascending frame numbers with runs joined, 0-3 5 7-8.
+func frameRanges(frames []int) string {
+	var parts []string
+	for i := 0; i < len(frames); {
+		j := i
+		for j+1 < len(frames) && frames[j+1] == frames[j]+1 {
+			j++
+		}
+		if j == i {
+			parts = append(parts, strconv.Itoa(frames[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%v-%v", frames[i], frames[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, " ")
+}
+
+// run renders the frames of cfg and returns the exit code.
+func run(ctx context.Context, cfg *RenderConfig) int {
+	zoom := viewport.Zoom{
+		Center: cfg.Center,
+		Width:  cfg.Radius.Mul(floatexp.New(2)),
+		Height: cfg.Radius.Mul(floatexp.New(2)),
+		Ratio:  cfg.ScaleRatio,
+	}
+
+	man, err := openManifest(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	policy := cfg.iterationPolicy()
+	coloring := cfg.coloring()
+	var prev *render.Stats
+	var completed []int
+	var skipped []string
+	for x := 0; x < cfg.ImageCount; x++ {
+		t1 := time.Now()
+		fname := fmt.Sprintf(cfg.Output, x)
+		bufName := ""
+		if cfg.Buffer != "" {
+			bufName = fmt.Sprintf(cfg.Buffer, x)
+		}
+		if cfg.Resume && man.done(x) && frameComplete(cfg, x) {
+			fmt.Printf("%v already done\n", fname)
+			// the iteration policy goes on from the finished frame
+			f := man.Frames[x]
+			prev = &render.Stats{MaxIter: f.MaxIter, Pixels: f.Pixels, Inside: f.Inside}
+			// so does the histogram smoothing
+			if coloring.Histogram != nil && bufName != "" {
+				buf, err := render.LoadBuffer(bufName)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					return 1
+				}
+				coloring.Histogram.Add(buf)
+			}
+			completed = append(completed, x)
+			continue
+		}
+		rectangle := zoom.Frame(x)
+
+		job := cfg.job(rectangle)
+		job.MaxIter = policy.MaxIter(&rectangle, prev)
+		buf, stats, err := render.Compute(ctx, job)
+		if ctx.Err() != nil {
+			break
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		prev = &stats
+		img := coloring.Paint(buf, cfg.MandelWorkers)
+		write := func() error {
+			if bufName != "" {
+				if err := writeBuffer(bufName, buf); err != nil {
+					return err
+				}
+			}
+			if cfg.NPY != "" {
+				if err := writeNPY(fmt.Sprintf(cfg.NPY, x), buf); err != nil {
+					return err
+				}
+			}
+			if cfg.Raw != "" {
+				if err := writeRaw(fmt.Sprintf(cfg.Raw, x), &job, buf); err != nil {
+					return err
+				}
+			}
+			if err := writePNG(fname, img); err != nil {
+				return err
+			}
+			return man.add(x, &stats)
+		}
+		err = write()
+		for retry := 1; err != nil && cfg.OnError == onErrorRetry && retry <= cfg.Retries; retry++ {
+			fmt.Fprintf(os.Stderr, "%v, retry %v of %v\n", err, retry, cfg.Retries)
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Duration(retry) * time.Second):
+				err = write()
+			}
+		}
+		// a frame that is written counts even when interrupted meanwhile
+		if err != nil && ctx.Err() != nil {
+			break
+		}
+		if err != nil && cfg.OnError == onErrorSkip {
+			fmt.Fprintf(os.Stderr, "%v, skipping %v\n", err, fname)
+			skipped = append(skipped, fname)
+			continue
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			printSummary(cfg, completed, skipped)
+			return 1
+		}
+		completed = append(completed, x)
+
+		fmt.Printf("%v took %v, maxiter %v", fname, time.Since(t1), stats.MaxIter)
+		if stats.Perturbation {
+			fmt.Printf(" (%v references, skipped %v iterations", stats.References, stats.Skipped)
+			if stats.Unresolved > 0 {
+				fmt.Printf(", %v glitched pixels computed with math/big", stats.Unresolved)
+			}
+			fmt.Print(")")
+		}
+		fmt.Println()
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	if ctx.Err() != nil {
+		fmt.Print("interrupted, ")
+		printSummary(cfg, completed, skipped)
+		return 130
+	}
+	if len(skipped) > 0 {
+		printSummary(cfg, completed, skipped)
+		return 1
+	}
+	return 0
+}
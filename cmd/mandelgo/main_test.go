@@ -0,0 +1,18 @@
+package main
+
+import "testing"
+
+func TestFrameRanges(t *testing.T) {
+	for _, tc := range []struct {
+		frames []int
+		want   string
+	}{
+		{[]int{4}, "4"},
+		{[]int{0, 1, 2, 3, 5, 7, 8}, "0-3 5 7-8"},
+		{[]int{1, 3}, "1 3"},
+	} {
+		if got := frameRanges(tc.frames); got != tc.want {
+			t.Errorf("frameRanges(%v) = %q, want %q", tc.frames, got, tc.want)
+		}
+	}
+}
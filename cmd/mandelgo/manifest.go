@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jfhaecker/mandelgo/render"
+)
+
+// manifest records which frames of a run are done and with which settings,
+// it lives next to the images. --resume only keeps frames of the manifest
+// and refuses to mix frames of other settings.
+type manifest struct {
+	fileName string
+	Job      string            `json:"job"` // hash of the settings that change the frames
+	Frames   map[int]frameInfo `json:"frames"`
+}
+
+type frameInfo struct {
+	MaxIter int `json:"max_iter"`
+	Pixels  int `json:"pixels"`
+	Inside  int `json:"inside"`
+}
+
+// manifestName is the output pattern with "manifest" for the image number
+// and a .json extension, mandel-manifest.json for mandel-%03v.png.
+func manifestName(cfg *RenderConfig) string {
+	pattern := cfg.Output
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '%' {
+			continue
+		}
+		if strings.HasPrefix(pattern[i+1:], "%") {
+			i++
+			continue
+		}
+		// the verb may be %d or %03v, it becomes %s
+		j := i + 1
+		for j < len(pattern) && strings.IndexByte("+-# .0123456789", pattern[j]) >= 0 {
+			j++
+		}
+		if j < len(pattern) {
+			pattern = pattern[:i] + "%s" + pattern[j+1:]
+		}
+		break
+	}
+	name := fmt.Sprintf(pattern, "manifest")
+	return strings.TrimSuffix(name, filepath.Ext(name)) + ".json"
+}
+
+// jobHash hashes the resolved job without the settings that leave the
+// frames alone, like the number of frames, workers or file names.
+func jobHash(cfg *RenderConfig) string {
+	c := *cfg
+	c.ImageCount, c.MandelWorkers, c.Resume = 0, 0, false
+	c.Output, c.Buffer, c.NPY, c.Raw = "", "", "", ""
+	c.OnError, c.Retries = "", 0
+	var b bytes.Buffer
+	writeJob(&b, &c)
+	return fmt.Sprintf("%x", sha256.Sum256(b.Bytes()))
+}
+
+// openManifest starts the manifest of a run. With --resume it continues the
+// one on disk, which must have been written with the same settings.
+func openManifest(cfg *RenderConfig) (*manifest, error) {
+	m := &manifest{fileName: manifestName(cfg), Job: jobHash(cfg), Frames: map[int]frameInfo{}}
+	if !cfg.Resume {
+		return m, nil
+	}
+	data, err := os.ReadFile(m.fileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var old manifest
+	if err := json.Unmarshal(data, &old); err != nil {
+		return nil, fmt.Errorf("%v: %w", m.fileName, err)
+	}
+	if old.Job != m.Job {
+		return nil, fmt.Errorf("%v: the images were rendered with other settings, remove them or render without --resume", m.fileName)
+	}
+	for x, f := range old.Frames {
+		m.Frames[x] = f
+	}
+	return m, nil
+}
+
+func (m *manifest) done(x int) bool {
+	_, ok := m.Frames[x]
+	return ok
+}
+
+// add records frame x and saves the manifest.
+func (m *manifest) add(x int, stats *render.Stats) error {
+	m.Frames[x] = frameInfo{stats.MaxIter, stats.Pixels, stats.Inside}
+	return writeFile(m.fileName, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(m)
+	})
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jfhaecker/mandelgo/render"
+)
+
+// imageComplete reports whether fileName is a readable PNG of the configured
+// size. Images are renamed into place only when fully written, so this is
+// enough to skip them on --resume.
+func imageComplete(fileName string, cfg *RenderConfig) bool {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	ic, err := png.DecodeConfig(f)
+	return err == nil && ic.Width == cfg.ImageWidth && ic.Height == cfg.ImageHeight
+}
+
+// exists reports whether fileName exists. Files are renamed into place only
+// when fully written, so this is enough for buffers and exports.
+func exists(fileName string) bool {
+	_, err := os.Stat(fileName)
+	return err == nil
+}
+
+// frameComplete reports whether the image of frame x and everything that
+// goes with it are written.
+func frameComplete(cfg *RenderConfig, x int) bool {
+	if !imageComplete(fmt.Sprintf(cfg.Output, x), cfg) {
+		return false
+	}
+	if cfg.Buffer != "" && !exists(fmt.Sprintf(cfg.Buffer, x)) {
+		return false
+	}
+	if cfg.NPY != "" && !exists(fmt.Sprintf(cfg.NPY, x)) {
+		return false
+	}
+	raw := fmt.Sprintf(cfg.Raw, x)
+	return cfg.Raw == "" || exists(raw) && exists(raw+".json")
+}
+
+// writePNG encodes img into fileName, see writeFile.
+func writePNG(fileName string, img image.Image) error {
+	return writeFile(fileName, func(w io.Writer) error { return png.Encode(w, img) })
+}
+
+// writeBuffer saves the iteration data of an image, see writeFile.
+func writeBuffer(fileName string, buf *render.Buffer) error {
+	return writeFile(fileName, func(w io.Writer) error {
+		_, err := buf.WriteTo(w)
+		return err
+	})
+}
+
+// writeNPY exports the iteration data of an image as NumPy array.
+func writeNPY(fileName string, buf *render.Buffer) error {
+	return writeFile(fileName, func(w io.Writer) error { return render.WriteNPY(w, buf) })
+}
+
+// writeRaw exports the iteration data of an image as raw arrays, the sidecar
+// goes to fileName.json.
+func writeRaw(fileName string, job *render.Job, buf *render.Buffer) error {
+	if err := writeFile(fileName, func(w io.Writer) error { return render.WriteRaw(w, buf) }); err != nil {
+		return err
+	}
+	return writeFile(fileName+".json", func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(render.NewSidecar(job, buf))
+	})
+}
+
+// writeFile writes into a temporary file next to fileName and renames it
+// once complete, so an interrupted run never leaves a truncated file.
+func writeFile(fileName string, write func(io.Writer) error) error {
+	dir := filepath.Dir(fileName)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(fileName)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after the rename
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return fmt.Errorf("encode %v: %w", fileName, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), fileName); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// syncDir makes the rename durable. Not every platform can sync a directory,
+// so only errors from opening it are reported.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	d.Sync()
+	return d.Close()
+}
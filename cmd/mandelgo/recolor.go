@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jfhaecker/mandelgo/palette"
+	"github.com/jfhaecker/mandelgo/render"
+)
+
+// recolorConfig holds the parameters of recolor, the coloring flags are the
+// same as for rendering.
+type recolorConfig struct {
+	*RenderConfig
+	Buffers []string
+}
+
+func parseRecolor(args []string, output io.Writer) (*recolorConfig, error) {
+	cfg := newRenderConfig()
+	cfg.Output = "%v.png"
+	fs := flag.NewFlagSet("mandelgo recolor", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: mandelgo recolor [flags] buffer...\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	addColoringFlags(fs, cfg)
+	fs.StringVar(&cfg.Output, "output", cfg.Output, "output file name pattern, gets the buffer file name without extension")
+	fs.IntVar(&cfg.MandelWorkers, "workers", cfg.MandelWorkers, "number of workers")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return nil, errors.New("recolor needs at least one buffer file")
+	}
+	if cfg.MandelWorkers < 1 {
+		return nil, errors.New("workers must be positive")
+	}
+	if err := cfg.validateColoring(); err != nil {
+		return nil, err
+	}
+	if err := validateOutput(cfg.Output); err != nil {
+		return nil, err
+	}
+	return &recolorConfig{cfg, fs.Args()}, nil
+}
+
+// recolor paints saved buffers with new coloring settings and returns the
+// exit code.
+func recolor(args []string) int {
+	rc, err := parseRecolor(args, os.Stderr)
+	if err == flag.ErrHelp {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	coloring := rc.coloring()
+	for _, name := range rc.Buffers {
+		t1 := time.Now()
+		buf, err := render.LoadBuffer(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v: %v\n", name, err)
+			return 1
+		}
+		fname := fmt.Sprintf(rc.Output, strings.TrimSuffix(name, filepath.Ext(name)))
+		if err := writePNG(fname, coloring.Paint(buf, rc.MandelWorkers)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Printf("%v took %v\n", fname, time.Since(t1))
+	}
+	return 0
+}
+
+// listPalettes loads the given palette files and prints every palette that
+// -palette can name, it returns the exit code.
+func listPalettes(files []string) int {
+	for _, f := range files {
+		if _, err := palette.Default.LoadFile(f); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	for _, name := range palette.Default.Names() {
+		g, _ := palette.Default.Lookup(name)
+		fmt.Printf("%-20v %v colors, %v\n", name, len(g.Stops), g.Space)
+	}
+	return 0
+}
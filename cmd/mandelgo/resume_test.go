@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func resumeConfig(dir string, count int) *RenderConfig {
+	cfg := newRenderConfig()
+	cfg.ImageWidth, cfg.ImageHeight, cfg.ImageCount = 16, 16, count
+	cfg.MaxIter, cfg.MandelWorkers = 200, 1
+	cfg.Output = filepath.Join(dir, "f%03v.png")
+	return cfg
+}
+
+func TestJobHash(t *testing.T) {
+	a, b := resumeConfig("a", 3), resumeConfig("b", 7)
+	b.MandelWorkers, b.Buffer = 4, "b%v.mgb"
+	if jobHash(a) != jobHash(b) {
+		t.Error("frame count, workers or file names changed the hash")
+	}
+	b.MaxIter++
+	if jobHash(a) == jobHash(b) {
+		t.Error("maxiter did not change the hash")
+	}
+}
+
+func TestManifestName(t *testing.T) {
+	for output, want := range map[string]string{
+		"mandel-%03v.png":   "mandel-manifest.json",
+		"x%d.png":           "xmanifest.json",
+		"100%%/f-%5.2x.png": "100%/f-manifest.json",
+	} {
+		if got := manifestName(&RenderConfig{Output: output}); got != want {
+			t.Errorf("manifestName(%q) = %q, want %q", output, got, want)
+		}
+	}
+}
+
+func TestResume(t *testing.T) {
+	dir := t.TempDir()
+	if code := run(context.Background(), resumeConfig(dir, 2)); code != 0 {
+		t.Fatalf("exit code %v", code)
+	}
+	first, err := os.Stat(filepath.Join(dir, "f000.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	cfg := resumeConfig(dir, 3)
+	cfg.Resume = true
+	if code := run(context.Background(), cfg); code != 0 {
+		t.Fatalf("exit code %v", code)
+	}
+	again, _ := os.Stat(filepath.Join(dir, "f000.png"))
+	if !again.ModTime().Equal(first.ModTime()) {
+		t.Error("resume rendered a finished frame again")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "f002.png")); err != nil {
+		t.Error("resume did not render the missing frame")
+	}
+
+	cfg.MaxIter = 300
+	if code := run(context.Background(), cfg); code != 2 {
+		t.Errorf("resume with other settings: exit code %v, want 2", code)
+	}
+}
+
+// Frames whose exports are missing are rendered again.
+func TestResumeExports(t *testing.T) {
+	dir := t.TempDir()
+	if code := run(context.Background(), resumeConfig(dir, 2)); code != 0 {
+		t.Fatalf("exit code %v", code)
+	}
+	cfg := resumeConfig(dir, 2)
+	cfg.Resume = true
+	cfg.NPY = filepath.Join(dir, "f%03v.npy")
+	cfg.Raw = filepath.Join(dir, "f%03v.raw")
+	if code := run(context.Background(), cfg); code != 0 {
+		t.Fatalf("exit code %v", code)
+	}
+	for _, name := range []string{"f000.npy", "f001.npy", "f000.raw", "f000.raw.json", "f001.raw.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("resume did not write %v", name)
+		}
+	}
+	os.Remove(filepath.Join(dir, "f001.raw.json"))
+	if frameComplete(cfg, 1) {
+		t.Error("frame without its sidecar is complete")
+	}
+}
+
+// A resumed adaptive run picks the same iterations as an uninterrupted one.
+func TestResumeAdaptive(t *testing.T) {
+	adaptive := func(dir string, count int) *RenderConfig {
+		cfg := resumeConfig(dir, count)
+		cfg.IterPolicy, cfg.AdaptTarget = iterAdaptive, 0
+		return cfg
+	}
+	whole, parts := t.TempDir(), t.TempDir()
+	run(context.Background(), adaptive(whole, 3))
+	run(context.Background(), adaptive(parts, 2))
+	cfg := adaptive(parts, 3)
+	cfg.Resume = true
+	if code := run(context.Background(), cfg); code != 0 {
+		t.Fatalf("exit code %v", code)
+	}
+	frames := func(dir string) map[int]frameInfo {
+		data, err := os.ReadFile(filepath.Join(dir, "fmanifest.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var m manifest
+		if err := json.Unmarshal(data, &m); err != nil {
+			t.Fatal(err)
+		}
+		return m.Frames
+	}
+	want, got := frames(whole), frames(parts)
+	if want[2].MaxIter <= want[1].MaxIter {
+		t.Fatalf("maxiter did not grow: %+v", want)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("resumed frames %+v, want %+v", got, want)
+	}
+}
+
+// A resumed run continues the histogram smoothing from the saved buffers.
+func TestResumeHistogram(t *testing.T) {
+	smoothed := func(dir string, count int) *RenderConfig {
+		cfg := resumeConfig(dir, count)
+		cfg.Histogram, cfg.HistSmoothing = true, 0.8
+		cfg.Buffer = filepath.Join(dir, "f%03v.mgb")
+		return cfg
+	}
+	whole, parts := t.TempDir(), t.TempDir()
+	run(context.Background(), smoothed(whole, 3))
+	run(context.Background(), smoothed(parts, 2))
+	cfg := smoothed(parts, 3)
+	cfg.Resume = true
+	if code := run(context.Background(), cfg); code != 0 {
+		t.Fatalf("exit code %v", code)
+	}
+	want, _ := os.ReadFile(filepath.Join(whole, "f002.png"))
+	got, _ := os.ReadFile(filepath.Join(parts, "f002.png"))
+	if len(want) == 0 || string(got) != string(want) {
+		t.Error("the resumed frame differs from the uninterrupted one")
+	}
+
+	cfg.Buffer = ""
+	if err := cfg.Validate(); err == nil {
+		t.Error("resume with histogram smoothing accepted without buffers")
+	}
+}
@@ -0,0 +1,198 @@
+// Package floatexp implements floating point numbers with a float64
+// mantissa and an extra int exponent. They have the precision of float64
+// but no practical limit on the range, deep zooms need deltas far below
+// 1e-308.
+package floatexp
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// Float is M * 2^E with 0.5 <= |M| < 1, or M == 0.
+type Float struct {
+	M float64
+	E int
+}
+
+func New(f float64) Float {
+	m, e := math.Frexp(f)
+	return Float{m, e}
+}
+
+func norm(m float64, e int) Float {
+	m, me := math.Frexp(m)
+	if m == 0 {
+		return Float{}
+	}
+	return Float{m, e + me}
+}
+
+// FromBig rounds f to a Float.
+func FromBig(f *big.Float) Float {
+	if f.Sign() == 0 {
+		return Float{}
+	}
+	mant := new(big.Float)
+	e := f.MantExp(mant)
+	m, _ := mant.Float64()
+	return norm(m, e)
+}
+
+// ParseFloat parses a decimal number of any magnitude.
+func ParseFloat(s string) (Float, error) {
+	f, _, err := big.ParseFloat(s, 10, 64, big.ToNearestEven)
+	if err != nil {
+		return Float{}, err
+	}
+	return FromBig(f), nil
+}
+
+// Float64 converts a to float64, it underflows to 0 or overflows to Inf.
+func (a Float) Float64() float64 {
+	return math.Ldexp(a.M, a.E)
+}
+
+// Big converts a to a big.Float, beyond its exponent range of about ±2^31
+// it overflows to ±Inf or underflows to 0.
+func (a Float) Big() *big.Float {
+	f := new(big.Float).SetFloat64(a.M)
+	return f.SetMantExp(f, a.E)
+}
+
+// Add rounds the same way as float64 as long as the result is not
+// subnormal in float64.
+func (a Float) Add(b Float) Float {
+	switch {
+	case a.M == 0:
+		return b
+	case b.M == 0:
+		return a
+	case a.E >= b.E:
+		return norm(a.M+math.Ldexp(b.M, b.E-a.E), a.E)
+	default:
+		return norm(math.Ldexp(a.M, a.E-b.E)+b.M, b.E)
+	}
+}
+
+func (a Float) Neg() Float {
+	return Float{-a.M, a.E}
+}
+
+func (a Float) Sub(b Float) Float {
+	return a.Add(b.Neg())
+}
+
+func (a Float) Mul(b Float) Float {
+	return norm(a.M*b.M, a.E+b.E)
+}
+
+func (a Float) Div(b Float) Float {
+	return norm(a.M/b.M, a.E-b.E)
+}
+
+func (a Float) Abs() Float {
+	return Float{math.Abs(a.M), a.E}
+}
+
+// Cmp returns -1, 0 or +1 like big.Float.Cmp.
+func (a Float) Cmp(b Float) int {
+	d := a.Sub(b).M
+	switch {
+	case d < 0:
+		return -1
+	case d > 0:
+		return 1
+	}
+	return 0
+}
+
+// Log2 returns log2(|a|).
+func (a Float) Log2() float64 {
+	return math.Log2(math.Abs(a.M)) + float64(a.E)
+}
+
+func (a Float) String() string {
+	if a.E > -1000 && a.E < 1000 {
+		return strconv.FormatFloat(a.Float64(), 'g', -1, 64)
+	}
+	return a.Big().Text('g', -1)
+}
+
+// Complex is (Re + Im*i) * 2^E with a shared exponent, the larger part is
+// normalized to 0.5 <= |x| < 1.
+type Complex struct {
+	Re, Im float64
+	E      int
+}
+
+func NewComplex(c complex128) Complex {
+	return normComplex(real(c), imag(c), 0)
+}
+
+// ComplexOf returns re + im*i.
+func ComplexOf(re, im Float) Complex {
+	return Complex{Re: re.M, E: re.E}.Add(Complex{Im: im.M, E: im.E})
+}
+
+func normComplex(re, im float64, e int) Complex {
+	_, me := math.Frexp(math.Max(math.Abs(re), math.Abs(im)))
+	if re == 0 && im == 0 {
+		return Complex{}
+	}
+	return Complex{math.Ldexp(re, -me), math.Ldexp(im, -me), e + me}
+}
+
+// Complex128 converts a to complex128, parts may underflow to 0.
+func (a Complex) Complex128() complex128 {
+	return complex(math.Ldexp(a.Re, a.E), math.Ldexp(a.Im, a.E))
+}
+
+func (a Complex) Real() Float {
+	return norm(a.Re, a.E)
+}
+
+func (a Complex) Imag() Float {
+	return norm(a.Im, a.E)
+}
+
+func (a Complex) IsZero() bool {
+	return a.Re == 0 && a.Im == 0
+}
+
+func (a Complex) IsNaN() bool {
+	return math.IsNaN(a.Re) || math.IsNaN(a.Im)
+}
+
+func (a Complex) Add(b Complex) Complex {
+	switch {
+	case a.IsZero():
+		return b
+	case b.IsZero():
+		return a
+	case a.E >= b.E:
+		s := b.E - a.E
+		return normComplex(a.Re+math.Ldexp(b.Re, s), a.Im+math.Ldexp(b.Im, s), a.E)
+	default:
+		s := a.E - b.E
+		return normComplex(math.Ldexp(a.Re, s)+b.Re, math.Ldexp(a.Im, s)+b.Im, b.E)
+	}
+}
+
+func (a Complex) Sub(b Complex) Complex {
+	return a.Add(Complex{-b.Re, -b.Im, b.E})
+}
+
+func (a Complex) Mul(b Complex) Complex {
+	return normComplex(a.Re*b.Re-a.Im*b.Im, a.Re*b.Im+a.Im*b.Re, a.E+b.E)
+}
+
+// Scale multiplies a by an ordinary complex number.
+func (a Complex) Scale(c complex128) Complex {
+	return normComplex(a.Re*real(c)-a.Im*imag(c), a.Re*imag(c)+a.Im*real(c), a.E)
+}
+
+func (a Complex) Abs() Float {
+	return norm(math.Hypot(a.Re, a.Im), a.E)
+}
@@ -0,0 +1,179 @@
+package floatexp
+
+import (
+	"math"
+	"math/big"
+	"math/cmplx"
+	"testing"
+)
+
+var float64s = []float64{0, 1, -1, 0.5, 1.5, -3.25, 7e-10, 1e300, -1e-300, math.Pi, 1 << 40}
+
+func TestNormalization(t *testing.T) {
+	for _, f := range float64s {
+		a := New(f)
+		if f == 0 && a != (Float{}) {
+			t.Errorf("New(0) = %v, want the zero Float", a)
+		}
+		if m := math.Abs(a.M); f != 0 && (m < 0.5 || m >= 1) {
+			t.Errorf("New(%v) = %+v, mantissa not normalized", f, a)
+		}
+		if a.Float64() != f {
+			t.Errorf("New(%v).Float64() = %v", f, a.Float64())
+		}
+	}
+	if a := New(3).Sub(New(3)); a != (Float{}) {
+		t.Errorf("3-3 = %+v, want the zero Float", a)
+	}
+	if a := New(0).Mul(Float{0.5, 1 << 40}); a != (Float{}) {
+		t.Errorf("0*2^(2^40) = %+v, want the zero Float", a)
+	}
+}
+
+// Within the range of float64 the results round like float64.
+func TestFloat64(t *testing.T) {
+	for _, x := range float64s {
+		for _, y := range float64s {
+			a, b := New(x), New(y)
+			for _, tc := range []struct {
+				op        string
+				got, want float64
+			}{
+				{"+", a.Add(b).Float64(), x + y},
+				{"-", a.Sub(b).Float64(), x - y},
+				{"*", a.Mul(b).Float64(), x * y},
+			} {
+				if tc.got != tc.want {
+					t.Errorf("%v %v %v = %v, want %v", x, tc.op, y, tc.got, tc.want)
+				}
+			}
+			if y != 0 && a.Div(b).Float64() != x/y {
+				t.Errorf("%v / %v = %v, want %v", x, y, a.Div(b).Float64(), x/y)
+			}
+			want := 0
+			if x < y {
+				want = -1
+			} else if x > y {
+				want = 1
+			}
+			if got := a.Cmp(b); got != want {
+				t.Errorf("Cmp(%v, %v) = %v, want %v", x, y, got, want)
+			}
+		}
+	}
+}
+
+// Far beyond the range of float64 the results round like a big.Float with
+// the precision of float64.
+func TestBig(t *testing.T) {
+	values := []Float{
+		{}, {0.5, 0}, {-0.75, 3}, {0.6, -1074}, {0.9, 5000}, {-0.55, -5000},
+		{0.7, -4990}, {0.8, 1 << 20}, {-0.5, -1 << 20}, {0.999, -1 << 20},
+	}
+	for _, a := range values {
+		for _, b := range values {
+			x, y := a.Big(), b.Big()
+			for _, tc := range []struct {
+				op   string
+				got  Float
+				want *big.Float
+			}{
+				{"+", a.Add(b), new(big.Float).SetPrec(53).Add(x, y)},
+				{"-", a.Sub(b), new(big.Float).SetPrec(53).Sub(x, y)},
+				{"*", a.Mul(b), new(big.Float).SetPrec(53).Mul(x, y)},
+			} {
+				if tc.got.Big().Cmp(tc.want) != 0 {
+					t.Errorf("%v %v %v = %v, want %v", a, tc.op, b, tc.got, tc.want.Text('g', 17))
+				}
+			}
+			if got, want := a.Cmp(b), x.Cmp(y); got != want {
+				t.Errorf("Cmp(%v, %v) = %v, want %v", a, b, got, want)
+			}
+			if r := FromBig(x); r != a {
+				t.Errorf("FromBig(%v) = %+v, want %+v", a, r, a)
+			}
+		}
+	}
+}
+
+// Float64 overflows to Inf and underflows to 0, the Float keeps the value.
+func TestRange(t *testing.T) {
+	huge := Float{0.5, 1000}.Mul(Float{0.5, 1000})
+	if huge.E != 1999 || !math.IsInf(huge.Float64(), 1) {
+		t.Errorf("2^999 * 2^999 = %+v, Float64 %v", huge, huge.Float64())
+	}
+	tiny := Float{-0.5, -1000}.Mul(Float{0.5, -1000})
+	if tiny.E != -2001 || tiny.Float64() != 0 {
+		t.Errorf("-2^-1001 * 2^-1001 = %+v, Float64 %v", tiny, tiny.Float64())
+	}
+	if tiny.Div(tiny) != New(1) {
+		t.Errorf("tiny/tiny = %+v", tiny.Div(tiny))
+	}
+	if got := huge.Add(tiny); got != huge {
+		t.Errorf("huge + tiny = %+v, want %+v", got, huge)
+	}
+	if got := tiny.Add(New(1)); got != New(1) {
+		t.Errorf("tiny + 1 = %+v, want 1", got)
+	}
+	if s := (Float{0.5, -4999}).String(); s != "7.079811261048173e-1506" {
+		t.Errorf("String() = %v", s)
+	}
+	// beyond the exponents of big.Float only Big gives up
+	far := Float{0.75, 1 << 40}.Mul(Float{-0.5, 1 << 40})
+	if far != (Float{-0.75, 1<<41 - 1}) || !far.Big().IsInf() || far.Big().Sign() > 0 {
+		t.Errorf("2^(2^40) products = %+v, Big %v", far, far.Big())
+	}
+	if got := far.Div(far); got != New(1) {
+		t.Errorf("far/far = %+v, want 1", got)
+	}
+	if got := New(8).Log2(); got != 3 {
+		t.Errorf("Log2(8) = %v", got)
+	}
+}
+
+func TestComplex(t *testing.T) {
+	values := []complex128{0, 1, 1i, -2.5 + 0.25i, 1e-200 - 3e-201i, 3e150 + 4e150i}
+	for _, x := range values {
+		a := NewComplex(x)
+		if m := math.Max(math.Abs(a.Re), math.Abs(a.Im)); x != 0 && (m < 0.5 || m >= 1) {
+			t.Errorf("NewComplex(%v) = %+v, not normalized", x, a)
+		}
+		for _, y := range values {
+			b := NewComplex(y)
+			for _, tc := range []struct {
+				op        string
+				got, want complex128
+			}{
+				{"+", a.Add(b).Complex128(), x + y},
+				{"-", a.Sub(b).Complex128(), x - y},
+				{"*", a.Mul(b).Complex128(), x * y},
+				{"scale", a.Scale(y).Complex128(), x * y},
+			} {
+				if cmplx.Abs(tc.got-tc.want) > 1e-15*cmplx.Abs(tc.want) {
+					t.Errorf("%v %v %v = %v, want %v", x, tc.op, y, tc.got, tc.want)
+				}
+			}
+		}
+		if got, want := a.Abs().Float64(), cmplx.Abs(x); math.Abs(got-want) > 1e-15*want {
+			t.Errorf("|%v| = %v, want %v", x, got, want)
+		}
+	}
+
+	// 3e-200*(1+i) squared underflows float64, the exponent keeps it
+	a := NewComplex(3e-200 + 3e-200i)
+	sq := a.Mul(a).Mul(a).Mul(a)
+	if sq.Complex128() != 0 {
+		t.Errorf("(%v)^4 = %v in float64, want 0", a, sq.Complex128())
+	}
+	re, im := sq.Real(), sq.Imag()
+	want, _ := ParseFloat("-324e-800")
+	if d := re.Sub(want).Abs().Div(want.Abs()).Float64(); d > 1e-15 || im.M != 0 {
+		t.Errorf("(%v)^4 = %v%+vi, want %v", a, re, im, want)
+	}
+	if z := ComplexOf(re, im); z != sq {
+		t.Errorf("ComplexOf(%v, %v) = %+v, want %+v", re, im, z, sq)
+	}
+	if z := a.Sub(a); !z.IsZero() || z.E != 0 {
+		t.Errorf("a-a = %+v, want zero", z)
+	}
+}
@@ -0,0 +1,48 @@
+package fractal
+
+import (
+	"math"
+	"math/big"
+	"math/cmplx"
+)
+
+// BigMandelbrot is Mandelbrot for a c given with arbitrary precision, the
+// orbit is computed with the precision of cx. It is a lot slower than
+// Mandelbrot and only needed once float64 cannot resolve the pixels. A trap
+// sees the orbit rounded to float64.
+func BigMandelbrot(point *Point, cx, cy *big.Float, maxIter int, bailoutRadius float64, trap Trap) *Point {
+	prec := cx.Prec()
+	zr := new(big.Float).SetPrec(prec).Set(cx)
+	zi := new(big.Float).SetPrec(prec).Set(cy)
+	zr2 := new(big.Float).SetPrec(prec)
+	zi2 := new(big.Float).SetPrec(prec)
+	t := new(big.Float).SetPrec(prec)
+	if trap != nil {
+		x, _ := cx.Float64()
+		y, _ := cy.Float64()
+		point.Trap = math.Inf(1)
+		point.trap(trap, complex(x, y))
+	}
+	for iter := 1; ; iter++ {
+		zr2.Mul(zr, zr)
+		zi2.Mul(zi, zi)
+		t.Mul(zr, zi)
+		zi.Add(t, t).Add(zi, cy)
+		zr.Sub(zr2, zi2).Add(zr, cx)
+		point.IterationCount = iter
+
+		x, _ := zr.Float64()
+		y, _ := zi.Float64()
+		if zz := complex(x, y); cmplx.Abs(zz) > bailoutRadius {
+			smooth(point, zz, iter, 2)
+			return point
+		}
+		if trap != nil {
+			point.trap(trap, complex(x, y))
+		}
+		if iter == maxIter {
+			point.Zn = complex(x, y)
+			return point
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package fractal
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Distance estimates the distance of c to the Mandelbrot set from z and
+// dz/dc after the point escaped:
+//
+//	|z| * log|z| / |dz/dc|
+//
+// Nothing of the set is closer than half the estimate, usually it is not
+// much farther than twice the estimate either, except near cusps. The larger
+// the bailout radius the better the estimate. For Julia sets dz/dc is the derivative by
+// the starting point. It returns 0 when the derivative is unusable.
+// https://en.wikipedia.org/wiki/Plotting_algorithms_for_the_Mandelbrot_set#Distance_estimates
+func Distance(z, dz complex128) float64 {
+	absz, absdz := cmplx.Abs(z), cmplx.Abs(dz)
+	if absdz == 0 || math.IsInf(absdz, 0) || math.IsNaN(absdz) || absz <= 1 {
+		return 0
+	}
+	return absz * math.Log(absz) / absdz
+}
+
+// escapeDerivative is escape that also tracks the derivative dz of z, which
+// starts at 1. Every iteration adds dc to it, 1 for the derivative by c of
+// the Mandelbrot set and 0 for the derivative by the starting point of a
+// Julia set. It also runs the hook of a trap, which sees every z of the
+// orbit inside the bailout radius starting with z; escape stays without it
+// for speed.
+func escapeDerivative(point *Point, z, c, dc complex128, maxIter int, bailoutRadius, tolerance float64, trap Trap) *Point {
+	zz, dz := z, complex(1, 0)
+	saved, lambda, power := zz, 0, 1
+	if trap != nil {
+		point.Trap = math.Inf(1)
+		point.trap(trap, zz)
+	}
+	for iter := 1; ; iter++ {
+		dz = 2*zz*dz + dc
+		zz = zz*zz + c
+		point.IterationCount = iter
+		absz := cmplx.Abs(zz)
+
+		if absz > bailoutRadius {
+			smooth(point, zz, iter, 2)
+			point.DZ = dz
+			point.Distance = Distance(zz, dz)
+			return point
+		}
+		if trap != nil {
+			point.trap(trap, zz)
+		}
+		if iter == maxIter {
+			point.Zn, point.DZ = zz, dz
+			return point
+		}
+		if tolerance > 0 {
+			lambda++
+			if math.Abs(real(zz)-real(saved)) < tolerance && math.Abs(imag(zz)-imag(saved)) < tolerance {
+				point.IterationCount = maxIter
+				point.Zn, point.DZ = zz, dz
+				point.Period = lambda
+				return point
+			}
+			if lambda == power {
+				saved, lambda, power = zz, 0, 2*power
+			}
+		}
+	}
+}
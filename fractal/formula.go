@@ -0,0 +1,119 @@
+package fractal
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// Formula is one step of an escape-time fractal.
+type Formula interface {
+	// Step returns the next z.
+	Step(z, c complex128) complex128
+	// Escaped reports whether the orbit left the bailout radius.
+	Escaped(z complex128, bailoutRadius float64) bool
+	// Degree is the exponent used for smooth iteration counts.
+	Degree() float64
+}
+
+// RadiusEscape is the usual escape test, the orbit has escaped once |z|
+// exceeds the bailout radius. The formulas here embed it.
+type RadiusEscape struct{}
+
+func (RadiusEscape) Escaped(z complex128, r float64) bool { return cmplx.Abs(z) > r }
+
+// Quadratic is z*z + c, the Mandelbrot set.
+type Quadratic struct{ RadiusEscape }
+
+func (Quadratic) Step(z, c complex128) complex128 { return z*z + c }
+func (Quadratic) Degree() float64                 { return 2 }
+
+// BurningShip is (|Re z| + i|Im z|)^2 + c.
+// https://en.wikipedia.org/wiki/Burning_Ship_fractal
+type BurningShip struct{ RadiusEscape }
+
+func (BurningShip) Step(z, c complex128) complex128 {
+	z = complex(math.Abs(real(z)), math.Abs(imag(z)))
+	return z*z + c
+}
+func (BurningShip) Degree() float64 { return 2 }
+
+// Tricorn is conj(z)^2 + c, also called Mandelbar.
+// https://en.wikipedia.org/wiki/Tricorn_(mathematics)
+type Tricorn struct{ RadiusEscape }
+
+func (Tricorn) Step(z, c complex128) complex128 {
+	z = cmplx.Conj(z)
+	return z*z + c
+}
+func (Tricorn) Degree() float64 { return 2 }
+
+// Multibrot is z^D + c for integer and real D > 1.
+// https://en.wikipedia.org/wiki/Multibrot_set
+type Multibrot struct {
+	RadiusEscape
+	D float64
+}
+
+func (m Multibrot) Step(z, c complex128) complex128 {
+	if n := int(m.D); float64(n) == m.D {
+		return ipow(z, n) + c
+	}
+	return cmplx.Pow(z, complex(m.D, 0)) + c
+}
+func (m Multibrot) Degree() float64 { return m.D }
+
+func ipow(z complex128, n int) complex128 {
+	p := complex(1, 0)
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			p *= z
+		}
+		z *= z
+	}
+	return p
+}
+
+// Celtic is |Re z^2| + i Im z^2 + c.
+type Celtic struct{ RadiusEscape }
+
+func (Celtic) Step(z, c complex128) complex128 {
+	z = z * z
+	return complex(math.Abs(real(z)), imag(z)) + c
+}
+func (Celtic) Degree() float64 { return 2 }
+
+// Buffalo is |Re z^2| + i|Im z^2| + c.
+type Buffalo struct{ RadiusEscape }
+
+func (Buffalo) Step(z, c complex128) complex128 {
+	z = z * z
+	return complex(math.Abs(real(z)), math.Abs(imag(z))) + c
+}
+func (Buffalo) Degree() float64 { return 2 }
+
+// FormulaNames lists the names understood by FormulaByName.
+var FormulaNames = []string{"mandelbrot", "burningship", "tricorn", "multibrot", "celtic", "buffalo"}
+
+// FormulaByName returns the formula with the given name. degree is only used
+// by multibrot.
+func FormulaByName(name string, degree float64) (Formula, error) {
+	switch name {
+	case "mandelbrot":
+		return Quadratic{}, nil
+	case "burningship":
+		return BurningShip{}, nil
+	case "tricorn", "mandelbar":
+		return Tricorn{}, nil
+	case "multibrot":
+		if degree <= 1 {
+			return nil, fmt.Errorf("multibrot degree must be greater than 1, not %v", degree)
+		}
+		return Multibrot{D: degree}, nil
+	case "celtic":
+		return Celtic{}, nil
+	case "buffalo":
+		return Buffalo{}, nil
+	}
+	return nil, fmt.Errorf("unknown formula %q", name)
+}
@@ -0,0 +1,62 @@
+package fractal
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestFormulaStep(t *testing.T) {
+	for _, tc := range []struct {
+		f      Formula
+		z, c   complex128
+		want   complex128
+		inside complex128 // a c that never escapes
+	}{
+		{Quadratic{}, 1 + 1i, 0.5, 0.5 + 2i, -1},
+		{BurningShip{}, -1 - 2i, 0, -3 + 4i, -1.75},
+		{Tricorn{}, 1 + 2i, 1i, -3 - 3i, -1},
+		{Multibrot{D: 3}, 1 + 1i, 0, -2 + 2i, 0.5i},
+		{Multibrot{D: 2.5}, 4, 1, 33, 0},
+		{Celtic{}, 1 + 2i, 0, 3 + 4i, -1},
+		{Buffalo{}, 1 - 2i, -1, 2 + 4i, -0.5},
+	} {
+		if got := tc.f.Step(tc.z, tc.c); cmplx.Abs(got-tc.want) > 1e-12 {
+			t.Errorf("%T%v: step of %v with c %v = %v, want %v", tc.f, tc.f, tc.z, tc.c, got, tc.want)
+		}
+		e := Escape{Formula: tc.f, BailoutRadius: 10}
+		if p := e.Iterate(&Point{Z: tc.inside}, 500); p.IterationCount != 500 {
+			t.Errorf("%T%v: %v escaped after %v iterations", tc.f, tc.f, tc.inside, p.IterationCount)
+		}
+		// |3^d + 3| > 10 for all degrees
+		if p := e.Iterate(&Point{Z: 3}, 500); p.IterationCount != 1 {
+			t.Errorf("%T%v: 3 escaped after %v iterations, want 1", tc.f, tc.f, p.IterationCount)
+		}
+	}
+}
+
+// square escapes once |Re z| or |Im z| exceeds the bailout radius.
+type square struct{ Quadratic }
+
+func (square) Escaped(z complex128, r float64) bool {
+	return math.Abs(real(z)) > r || math.Abs(imag(z)) > r
+}
+
+// Escape uses the escape test of the formula.
+func TestFormulaEscaped(t *testing.T) {
+	// the orbit of 0 starts with 2.5+2.5i, within the square but not
+	// the circle of radius 3
+	const c = 2.5 + 2.5i
+	for _, tc := range []struct {
+		f    Formula
+		want int
+	}{
+		{Quadratic{}, 1},
+		{square{}, 2},
+	} {
+		e := Escape{Formula: tc.f, BailoutRadius: 3, Julia: true, C: c}
+		if p := e.Iterate(&Point{}, 100); p.IterationCount != tc.want {
+			t.Errorf("%T: escaped after %v iterations, want %v", tc.f, p.IterationCount, tc.want)
+		}
+	}
+}
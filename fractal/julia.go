@@ -0,0 +1,8 @@
+package fractal
+
+// Julia iterates z = z*z + c starting with z = point.Z for a fixed c. The
+// escape data is the same as for Mandelbrot, so palettes work unchanged.
+// https://en.wikipedia.org/wiki/Julia_set#Quadratic_polynomials
+func Julia(point *Point, c complex128, maxIter int, bailoutRadius float64) *Point {
+	return escape(point, point.Z, c, maxIter, bailoutRadius, 0)
+}
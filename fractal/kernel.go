@@ -0,0 +1,75 @@
+package fractal
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Kernel computes the escape data of a single point.
+type Kernel interface {
+	Iterate(point *Point, maxIter int) *Point
+}
+
+// Escape is the escape-time kernel for any Formula. In Julia mode the orbit
+// starts at point.Z with the fixed constant C, otherwise c = point.Z.
+type Escape struct {
+	Formula       Formula
+	BailoutRadius float64
+	Julia         bool
+	C             complex128
+	// Periodicity is the tolerance of the cycle detection for the
+	// Quadratic formula, 0 disables it.
+	Periodicity float64
+	// Derivative tracks dz/dc for the Quadratic formula and sets
+	// Point.DZ and Point.Distance, see Distance.
+	Derivative bool
+	// Trap sets Point.Trap and Point.TrapZ, nil disables it.
+	Trap Trap
+}
+
+func (e Escape) Iterate(point *Point, maxIter int) *Point {
+	c := point.Z
+	if e.Julia {
+		c = e.C
+	}
+	if _, ok := e.Formula.(Quadratic); ok {
+		switch {
+		case !e.Julia:
+			return mandelbrot(point, maxIter, e.BailoutRadius, e.Periodicity, e.Derivative, e.Trap)
+		case e.Derivative || e.Trap != nil:
+			return escapeDerivative(point, point.Z, c, 0, maxIter, e.BailoutRadius, e.Periodicity, e.Trap)
+		}
+		return escape(point, point.Z, c, maxIter, e.BailoutRadius, e.Periodicity)
+	}
+	zz := point.Z
+	if e.Trap != nil {
+		point.Trap = math.Inf(1)
+		point.trap(e.Trap, zz)
+	}
+	for iter := 1; ; iter++ {
+		zz = e.Formula.Step(zz, c)
+		point.IterationCount = iter
+
+		if e.Formula.Escaped(zz, e.BailoutRadius) {
+			smooth(point, zz, iter, e.Formula.Degree())
+			return point
+		}
+		if e.Trap != nil {
+			point.trap(e.Trap, zz)
+		}
+		if iter == maxIter {
+			point.Zn = zz
+			return point
+		}
+	}
+}
+
+// smooth sets the normalized iteration count for a point that escaped at
+// iteration iter. The usual log(2) generalizes to log(degree).
+// https://linas.org/art-gallery/escape/escape.html
+func smooth(point *Point, z complex128, iter int, degree float64) {
+	point.Zn = z
+	log_zn := math.Log10(cmplx.Abs(z))
+	nu := math.Log10(log_zn/math.Log10(2)) / math.Log10(degree)
+	point.NormIterationCount = float64(float64(iter) + 1.0 - nu)
+}
@@ -0,0 +1,76 @@
+package fractal
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Mandelbrot iterates z = z*z + c with c = point.Z until |z| exceeds the
+// bailout radius or maxIter is reached.
+// https://en.wikipedia.org/wiki/Mandelbrot_set#Continuous_.28smooth.29_coloring
+func Mandelbrot(point *Point, maxIter int, bailoutRadius float64) *Point {
+	return mandelbrot(point, maxIter, bailoutRadius, 0, false, nil)
+}
+
+func mandelbrot(point *Point, maxIter int, bailoutRadius, tolerance float64, derivative bool, trap Trap) *Point {
+	// with a trap the inside of the set is colored by its orbits too
+	if trap == nil && inMainBulbs(point.Z) {
+		point.IterationCount = maxIter
+		return point
+	}
+	if derivative || trap != nil {
+		return escapeDerivative(point, point.Z, point.Z, 1, maxIter, bailoutRadius, tolerance, trap)
+	}
+	return escape(point, point.Z, point.Z, maxIter, bailoutRadius, tolerance)
+}
+
+// inMainBulbs tells if c lies in the main cardioid or the period-2 bulb,
+// those points never escape.
+// https://en.wikipedia.org/wiki/Plotting_algorithms_for_the_Mandelbrot_set#Cardioid_/_bulb_checking
+func inMainBulbs(c complex128) bool {
+	x, y := real(c), imag(c)
+	y2 := y * y
+	q := (x-0.25)*(x-0.25) + y2
+	if q*(q+(x-0.25)) <= 0.25*y2 {
+		return true
+	}
+	return (x+1)*(x+1)+y2 <= 1.0/16
+}
+
+// escape is Escape.Iterate for the Quadratic formula without the interface
+// calls in the inner loop. With a tolerance > 0 it looks for cycles with
+// Brent's algorithm: the orbit is compared to a saved value that is replaced
+// after 1, 2, 4, 8... iterations. Once it comes back within tolerance the
+// point is periodic and can never escape, it is finished with maxIter
+// iterations right away.
+// https://en.wikipedia.org/wiki/Cycle_detection#Brent's_algorithm
+func escape(point *Point, z, c complex128, maxIter int, bailoutRadius, tolerance float64) *Point {
+	zz := z
+	saved, lambda, power := zz, 0, 1
+	for iter := 1; ; iter++ {
+		zz = zz*zz + c
+		point.IterationCount = iter
+		absz := cmplx.Abs(zz)
+
+		if absz > bailoutRadius {
+			smooth(point, zz, iter, 2)
+			return point
+		}
+		if iter == maxIter {
+			point.Zn = zz
+			return point
+		}
+		if tolerance > 0 {
+			lambda++
+			if math.Abs(real(zz)-real(saved)) < tolerance && math.Abs(imag(zz)-imag(saved)) < tolerance {
+				point.IterationCount = maxIter
+				point.Zn = zz
+				point.Period = lambda
+				return point
+			}
+			if lambda == power {
+				saved, lambda, power = zz, 0, 2*power
+			}
+		}
+	}
+}
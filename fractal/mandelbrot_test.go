@@ -0,0 +1,153 @@
+package fractal
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jfhaecker/mandelgo/viewport"
+)
+
+// benchViews include the main cardioid or the period-2 bulb.
+var benchViews = []struct {
+	name string
+	loc  viewport.Location
+}{
+	{"full", viewport.Location{X: -0.75, Y: 0, R: 1.5}},
+	{"location-6", viewport.Locations[6]},
+	{"location-10", viewport.Locations[10]},
+}
+
+func benchmarkView(b *testing.B, loc viewport.Location, kernel func(*Point, int, float64) *Point) {
+	const size = 200
+	view := viewport.Rectangle{}
+	view.Set(complex(loc.X, loc.Y), 2*loc.R, 2*loc.R)
+	for i := 0; i < b.N; i++ {
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				kernel(&Point{Z: view.At(x, y, size, size)}, 1000, 20)
+			}
+		}
+	}
+}
+
+func BenchmarkMandelbrot(b *testing.B) {
+	for _, v := range benchViews {
+		b.Run(v.name, func(b *testing.B) {
+			benchmarkView(b, v.loc, Mandelbrot)
+		})
+	}
+}
+
+// BenchmarkMandelbrotNoEarlyOut iterates every point to the end.
+func BenchmarkMandelbrotNoEarlyOut(b *testing.B) {
+	noEarlyOut := func(point *Point, maxIter int, bailoutRadius float64) *Point {
+		return escape(point, point.Z, point.Z, maxIter, bailoutRadius, 0)
+	}
+	for _, v := range benchViews {
+		b.Run(v.name, func(b *testing.B) {
+			benchmarkView(b, v.loc, noEarlyOut)
+		})
+	}
+}
+
+func TestMainBulbsNeverEscape(t *testing.T) {
+	view := viewport.Rectangle{}
+	view.Set(complex(-0.75, 0), 3, 3)
+	const size = 300
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			c := view.At(x, y, size, size)
+			var early, full Point
+			early.Z, full.Z = c, c
+			Mandelbrot(&early, 1000, 20)
+			escape(&full, c, c, 1000, 20, 0)
+			if early.IterationCount != full.IterationCount {
+				t.Errorf("%v: %v iterations with early-out, %v without", c, early.IterationCount, full.IterationCount)
+			}
+		}
+	}
+}
+
+// periodicityViews mix exterior with minibrots and other interior regions
+// outside the main bulbs.
+var periodicityViews = []viewport.Location{
+	{X: -0.75, Y: 0, R: 1.5},
+	{X: -1.7548776662466927, Y: 0, R: 0.01}, // period 3 minibrot
+	viewport.Locations[6],
+	viewport.Locations[11],
+	viewport.Locations[18],
+}
+
+func TestPeriodicityKeepsExterior(t *testing.T) {
+	const size, maxIter = 150, 2000
+	for _, loc := range periodicityViews {
+		view := viewport.Rectangle{}
+		view.Set(complex(loc.X, loc.Y), 2*loc.R, 2*loc.R)
+		detected := 0
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				c := view.At(x, y, size, size)
+				plain := *escape(&Point{Z: c}, c, c, maxIter, 20, 0)
+				periodic := *escape(&Point{Z: c}, c, c, maxIter, 20, 1e-12)
+				if periodic.Period > 0 {
+					detected++
+					if plain.IterationCount != maxIter {
+						t.Errorf("%v: period %v detected but escapes after %v iterations", c, periodic.Period, plain.IterationCount)
+					}
+					// the orbit stopped early
+					periodic.Period, periodic.Zn = 0, plain.Zn
+				}
+				if periodic != plain {
+					t.Errorf("%v: %+v with periodicity, %+v without", c, periodic, plain)
+				}
+			}
+		}
+		t.Logf("%v: %v of %v points periodic", loc, detected, size*size)
+	}
+}
+
+func TestPeriodicityFindsPeriod(t *testing.T) {
+	for _, tt := range []struct {
+		c      complex128
+		period int
+	}{
+		{0, 1},
+		{-1, 2},
+		{-0.1225611668766536 + 0.7448617666197442i, 3}, // rabbit
+		{-1.7548776662466927, 3},
+		{-0.15652016683375508 + 1.0322471089228318i, 4},
+	} {
+		p := escape(&Point{Z: tt.c}, tt.c, tt.c, 10000, 20, 1e-12)
+		if p.IterationCount != 10000 || p.Period != tt.period {
+			t.Errorf("%v: period %v after %v iterations, want %v", tt.c, p.Period, p.IterationCount, tt.period)
+		}
+	}
+}
+
+func TestPeriodicityDisabled(t *testing.T) {
+	p := escape(&Point{}, 0, 0, 100, 20, 0)
+	if p.Period != 0 || p.IterationCount != 100 {
+		t.Errorf("period %v after %v iterations without periodicity checking", p.Period, p.IterationCount)
+	}
+}
+
+// On the real axis the distance to the set is known outside of [-2, 1/4],
+// the Julia set of 0 is the unit circle.
+func TestDistanceBounds(t *testing.T) {
+	for _, c := range []float64{-3, -2.1, -2.01, 0.26, 0.3, 1, 2} {
+		p := mandelbrot(&Point{Z: complex(c, 0)}, 1000, 1e10, 0, true, nil)
+		if truth := math.Max(-2-c, c-0.25); p.Distance <= 0 || p.Distance/2 > truth {
+			t.Errorf("%v: distance %v, true distance %v", c, p.Distance, truth)
+		}
+		if math.Abs(c) <= 1 {
+			continue
+		}
+		p = escapeDerivative(&Point{}, complex(c, 0), 0, 0, 1000, 1e10, 0, nil)
+		if truth := math.Abs(c) - 1; p.Distance/2 > truth || 2*p.Distance < truth {
+			t.Errorf("julia %v: distance %v, true distance %v", c, p.Distance, truth)
+		}
+	}
+	if p := mandelbrot(&Point{Z: -1}, 1000, 20, 0, true, nil); p.Distance != 0 {
+		t.Errorf("-1 is inside but has distance %v", p.Distance)
+	}
+}
@@ -0,0 +1,231 @@
+package fractal
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+	"strconv"
+	"strings"
+)
+
+// Polynomial holds the coefficients of a polynomial, highest degree first.
+type Polynomial []complex128
+
+// ParsePolynomial understands a coefficient list like "1, 0, 0, -1" or a
+// sum of terms in z like "z^3 - 2z + 2" or "(1+2i)z^2 - 1".
+func ParsePolynomial(s string) (Polynomial, error) {
+	s = strings.ReplaceAll(s, " ", "")
+	var p Polynomial
+	if strings.Contains(s, ",") {
+		for _, f := range strings.Split(s, ",") {
+			c, err := strconv.ParseComplex(f, 128)
+			if err != nil {
+				return nil, fmt.Errorf("polynomial: bad coefficient %q", f)
+			}
+			p = append(p, c)
+		}
+	} else {
+		coef := map[int]complex128{}
+		terms, err := splitTerms(s)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range terms {
+			c, n, err := parseTerm(t)
+			if err != nil {
+				return nil, err
+			}
+			coef[n] += c
+		}
+		degree := 0
+		for n := range coef {
+			degree = max(degree, n)
+		}
+		p = make(Polynomial, degree+1)
+		for n, c := range coef {
+			p[degree-n] = c
+		}
+	}
+	for len(p) > 0 && p[0] == 0 {
+		p = p[1:]
+	}
+	if len(p) < 3 {
+		return nil, errors.New("polynomial: degree must be at least 2")
+	}
+	return p, nil
+}
+
+// splitTerms splits at + and - outside of parentheses and exponents.
+func splitTerms(s string) ([]string, error) {
+	var terms []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '+', '-':
+			if depth == 0 && i > start && s[i-1] != 'e' && s[i-1] != 'E' && s[i-1] != '^' {
+				terms = append(terms, s[start:i])
+				start = i
+			}
+		}
+	}
+	if depth != 0 || start == len(s) {
+		return nil, fmt.Errorf("polynomial: malformed %q", s)
+	}
+	return append(terms, s[start:]), nil
+}
+
+func parseTerm(t string) (complex128, int, error) {
+	coef, power, hasZ := t, "", false
+	if i := strings.IndexByte(t, 'z'); i >= 0 {
+		coef, power, hasZ = t[:i], t[i+1:], true
+	}
+	n := 0
+	if hasZ {
+		n = 1
+		if power != "" {
+			if power[0] != '^' {
+				return 0, 0, fmt.Errorf("polynomial: bad term %q", t)
+			}
+			var err error
+			if n, err = strconv.Atoi(power[1:]); err != nil || n < 0 {
+				return 0, 0, fmt.Errorf("polynomial: bad exponent in %q", t)
+			}
+		}
+	}
+	sign := complex(1, 0)
+	if strings.HasPrefix(coef, "-") {
+		sign = -1
+	}
+	if len(coef) > 0 && (coef[0] == '+' || coef[0] == '-') {
+		coef = coef[1:]
+	}
+	coef = strings.TrimSuffix(coef, "*")
+	if coef == "" && hasZ {
+		return sign, n, nil
+	}
+	c, err := strconv.ParseComplex(coef, 128)
+	if err != nil {
+		return 0, 0, fmt.Errorf("polynomial: bad coefficient in %q", t)
+	}
+	return sign * c, n, nil
+}
+
+func (p Polynomial) String() string {
+	var b strings.Builder
+	for i, c := range p {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString(strconv.FormatComplex(c, 'g', -1, 128))
+	}
+	return b.String()
+}
+
+// Eval returns p(z) and p'(z) using Horner's method.
+func (p Polynomial) Eval(z complex128) (v, dv complex128) {
+	for _, c := range p {
+		dv = dv*z + v
+		v = v*z + c
+	}
+	return v, dv
+}
+
+// Roots finds all complex roots with the Durand-Kerner method.
+// https://en.wikipedia.org/wiki/Durand%E2%80%93Kerner_method
+func (p Polynomial) Roots() []complex128 {
+	n := len(p) - 1
+	monic := make(Polynomial, len(p))
+	for i, c := range p {
+		monic[i] = c / p[0]
+	}
+	roots := make([]complex128, n)
+	seed := complex(0.4, 0.9)
+	roots[0] = 1
+	for i := 1; i < n; i++ {
+		roots[i] = roots[i-1] * seed
+	}
+	for iter := 0; iter < 1000; iter++ {
+		change := 0.0
+		for i := range roots {
+			v, _ := monic.Eval(roots[i])
+			d := complex(1, 0)
+			for j := range roots {
+				if i != j {
+					d *= roots[i] - roots[j]
+				}
+			}
+			step := v / d
+			roots[i] -= step
+			change = math.Max(change, cmplx.Abs(step))
+		}
+		if change < 1e-14 {
+			break
+		}
+	}
+	return roots
+}
+
+// Newton is the kernel for Newton's method on a polynomial. Instead of an
+// escape radius the orbit stops once a step is shorter than Tolerance,
+// point.Root is then set to the 1-based index of the root it converged to.
+// https://en.wikipedia.org/wiki/Newton_fractal
+type Newton struct {
+	Poly      Polynomial
+	Tolerance float64
+	Roots     []complex128
+}
+
+func NewNewton(poly Polynomial, tolerance float64) (*Newton, error) {
+	if tolerance <= 0 {
+		return nil, errors.New("newton: tolerance must be positive")
+	}
+	return &Newton{Poly: poly, Tolerance: tolerance, Roots: poly.Roots()}, nil
+}
+
+func (n *Newton) Iterate(point *Point, maxIter int) *Point {
+	z := point.Z
+	prev := math.Inf(1)
+	for iter := 1; ; iter++ {
+		point.IterationCount = iter
+		v, dv := n.Poly.Eval(z)
+		if dv == 0 {
+			point.IterationCount = maxIter
+			point.Zn = z
+			return point
+		}
+		step := v / dv
+		z -= step
+		point.Zn = z
+		d := cmplx.Abs(step)
+
+		if d < n.Tolerance {
+			point.Root = n.nearestRoot(z) + 1
+			// interpolate in log space where the step crossed the tolerance
+			t := 1.0
+			if !math.IsInf(prev, 1) && d > 0 {
+				t = (math.Log(n.Tolerance) - math.Log(prev)) / (math.Log(d) - math.Log(prev))
+			}
+			point.NormIterationCount = float64(iter-1) + t
+			return point
+		}
+		if iter == maxIter {
+			return point
+		}
+		prev = d
+	}
+}
+
+func (n *Newton) nearestRoot(z complex128) int {
+	best, dist := 0, math.Inf(1)
+	for i, r := range n.Roots {
+		if d := cmplx.Abs(z - r); d < dist {
+			best, dist = i, d
+		}
+	}
+	return best
+}
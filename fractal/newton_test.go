@@ -0,0 +1,93 @@
+package fractal
+
+import (
+	"math/cmplx"
+	"reflect"
+	"testing"
+)
+
+func TestParsePolynomial(t *testing.T) {
+	for _, tc := range []struct {
+		s    string
+		want Polynomial
+	}{
+		{"z^3-1", Polynomial{1, 0, 0, -1}},
+		{"1, 0, 0, -1", Polynomial{1, 0, 0, -1}},
+		{"0, 1, 0, -1", Polynomial{1, 0, -1}},
+		{"z^3 - 2z + 2", Polynomial{1, 0, -2, 2}},
+		{"(1+2i)z^2 - 1", Polynomial{1 + 2i, 0, -1}},
+		{"2*z^2 + z", Polynomial{2, 1, 0}},
+		{"z^2 + 1e-3", Polynomial{1, 0, 1e-3}},
+		{"-z^2 + z^4 + 2z^2", Polynomial{1, 0, 1, 0, 0}},
+	} {
+		got, err := ParsePolynomial(tc.s)
+		if err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("ParsePolynomial(%q) = %v, %v, want %v", tc.s, got, err, tc.want)
+		}
+	}
+	for _, s := range []string{"", "z", "z+1", "z^2+", "(z^2", "z^-2", "zz", "z^a", "1, x", "1, 2", "z^2 - z^2 + z"} {
+		if p, err := ParsePolynomial(s); err == nil {
+			t.Errorf("ParsePolynomial(%q) = %v, want an error", s, p)
+		}
+	}
+}
+
+func TestEval(t *testing.T) {
+	v, dv := Polynomial{1, 0, 0, -1}.Eval(2)
+	if v != 7 || dv != 12 {
+		t.Errorf("z^3-1 at 2 = %v, derivative %v, want 7 and 12", v, dv)
+	}
+}
+
+// Durand-Kerner finds every root once.
+func TestRoots(t *testing.T) {
+	unity := cmplx.Rect(1, 2*cmplx.Phase(-1)/3)
+	for _, tc := range []struct {
+		p     Polynomial
+		roots []complex128
+	}{
+		{Polynomial{1, 0, 0, -1}, []complex128{1, unity, cmplx.Conj(unity)}},
+		// 2(z-1)(z-2)(z+3i)
+		{Polynomial{2, -6 + 6i, 4 - 18i, 12i}, []complex128{1, 2, -3i}},
+		{Polynomial{1, 0, 1}, []complex128{1i, -1i}},
+	} {
+		got := tc.p.Roots()
+		if len(got) != len(tc.roots) {
+			t.Fatalf("%v: %v roots, want %v", tc.p, len(got), len(tc.roots))
+		}
+		for _, want := range tc.roots {
+			found := 0
+			for _, r := range got {
+				if cmplx.Abs(r-want) < 1e-9 {
+					found++
+				}
+			}
+			if found != 1 {
+				t.Errorf("%v: root %v found %v times in %v", tc.p, want, found, got)
+			}
+		}
+	}
+}
+
+func TestNewton(t *testing.T) {
+	if _, err := NewNewton(Polynomial{1, 0, -1}, 0); err == nil {
+		t.Error("tolerance 0 accepted")
+	}
+	n, err := NewNewton(Polynomial{1, 0, 0, -1}, 1e-6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, root := range n.Roots {
+		p := n.Iterate(&Point{Z: root * (1.3 + 0.1i)}, 100)
+		if p.Root != i+1 || p.IterationCount == 100 || cmplx.Abs(p.Zn-root) > 1e-6 {
+			t.Errorf("start near %v: root %v at %v after %v iterations, want %v", root, p.Root, p.Zn, p.IterationCount, i+1)
+		}
+		if p.NormIterationCount <= float64(p.IterationCount-1) || p.NormIterationCount > float64(p.IterationCount) {
+			t.Errorf("start near %v: smooth count %v after %v iterations", root, p.NormIterationCount, p.IterationCount)
+		}
+	}
+	// the derivative vanishes at 0
+	if p := n.Iterate(&Point{}, 100); p.Root != 0 || p.IterationCount != 100 {
+		t.Errorf("0: root %v after %v iterations, want none", p.Root, p.IterationCount)
+	}
+}
@@ -0,0 +1,232 @@
+package fractal
+
+import (
+	"math"
+	"math/big"
+	"math/cmplx"
+
+	"github.com/jfhaecker/mandelgo/floatexp"
+)
+
+// glitchTolerance is the Pauldelbrot criterion: a pixel is glitched when
+// |Z+d| < glitchTolerance*|Z|, its delta then lost all precision.
+// https://fractalforums.org/index.php?topic=4360.0
+const glitchTolerance = 1e-3
+
+// Orbit is a reference orbit for perturbation. It is computed with
+// arbitrary precision at C but stored as float64, the values never leave
+// the bailout radius by much.
+// https://en.wikipedia.org/wiki/Plotting_algorithms_for_the_Mandelbrot_set#Perturbation_theory_and_series_approximation
+type Orbit struct {
+	CX, CY *big.Float
+	Z      []complex128 // Z[0] = C, Z[n] after n iterations
+	// Derivative makes IterateFrom track dz/dc of the pixels in Point.DZ,
+	// it must not be set for IterateExp.
+	Derivative bool
+	// Trap makes IterateFrom and IterateExp record the closest approach of
+	// the orbit to it from iteration n on, Point.Trap has to start at +Inf.
+	Trap Trap
+}
+
+// NewOrbit iterates C = cx + cy*i until it escapes or maxIter is reached.
+func NewOrbit(cx, cy *big.Float, maxIter int, bailoutRadius float64) *Orbit {
+	prec := cx.Prec()
+	zr := new(big.Float).SetPrec(prec).Set(cx)
+	zi := new(big.Float).SetPrec(prec).Set(cy)
+	zr2 := new(big.Float).SetPrec(prec)
+	zi2 := new(big.Float).SetPrec(prec)
+	t := new(big.Float).SetPrec(prec)
+
+	orbit := &Orbit{CX: cx, CY: cy, Z: make([]complex128, 1, maxIter+1)}
+	x, _ := cx.Float64()
+	y, _ := cy.Float64()
+	orbit.Z[0] = complex(x, y)
+	for iter := 1; iter <= maxIter; iter++ {
+		zr2.Mul(zr, zr)
+		zi2.Mul(zi, zi)
+		t.Mul(zr, zi)
+		zi.Add(t, t).Add(zi, cy)
+		zr.Sub(zr2, zi2).Add(zr, cx)
+		x, _ := zr.Float64()
+		y, _ := zi.Float64()
+		orbit.Z = append(orbit.Z, complex(x, y))
+		if cmplx.Abs(complex(x, y)) > bailoutRadius {
+			break
+		}
+	}
+	return orbit
+}
+
+func norm(z complex128) float64 {
+	return real(z)*real(z) + imag(z)*imag(z)
+}
+
+// Iterate computes point as C+dc using only float64 deltas against the
+// reference orbit:
+//
+//	d[n+1] = 2*Z[n]*d[n] + d[n]^2 + dc
+//
+// A glitched point has to be iterated again with another reference, closeness
+// tells how deep the orbit fell into the glitch, lower is deeper.
+func (o *Orbit) Iterate(point *Point, dc complex128, maxIter int, bailoutRadius float64) (glitched bool, closeness float64) {
+	point.DZ, point.Trap = 1, math.Inf(1)
+	return o.IterateFrom(point, 0, dc, dc, maxIter, bailoutRadius)
+}
+
+// IterateFrom is Iterate starting at iteration n with the delta d, see
+// Series. With Derivative point.DZ has to hold dz/dc at iteration n, see
+// Series.Derivative.
+func (o *Orbit) IterateFrom(point *Point, n int, d, dc complex128, maxIter int, bailoutRadius float64) (glitched bool, closeness float64) {
+	last := len(o.Z) - 1
+	bailout2 := bailoutRadius * bailoutRadius
+	if o.Trap != nil && n <= last {
+		point.trap(o.Trap, o.Z[n]+d)
+	}
+	for iter := n + 1; ; iter++ {
+		if iter > last {
+			// the reference escaped before this point
+			return true, math.Inf(1)
+		}
+		if o.Derivative {
+			point.DZ = 2*(o.Z[iter-1]+d)*point.DZ + 1
+		}
+		d = 2*o.Z[iter-1]*d + d*d + dc
+		zz := o.Z[iter] + d
+		point.IterationCount = iter
+		n := norm(zz)
+
+		if n > bailout2 {
+			smooth(point, zz, iter, 2)
+			if o.Derivative {
+				point.Distance = Distance(zz, point.DZ)
+			}
+			return false, 0
+		}
+		if ref := norm(o.Z[iter]); n < glitchTolerance*glitchTolerance*ref {
+			return true, math.Sqrt(n / ref)
+		}
+		if o.Trap != nil {
+			point.trap(o.Trap, zz)
+		}
+		if iter == maxIter {
+			point.Zn = zz
+			return false, 0
+		}
+	}
+}
+
+// MinExp is the binary exponent below which deltas are kept in floatexp,
+// below it float64 loses precision to subnormals or underflows to 0.
+const MinExp = -960
+
+// IterateExp is IterateFrom for deltas too small for float64. It iterates in
+// floatexp until the delta has grown above 2^MinExp and continues with
+// IterateFrom, by then dc is either representable or negligible. It does
+// not track the derivative, dz/dc exceeds float64 at such depths.
+func (o *Orbit) IterateExp(point *Point, n int, d, dc floatexp.Complex, maxIter int, bailoutRadius float64) (glitched bool, closeness float64) {
+	last := len(o.Z) - 1
+	bailout2 := bailoutRadius * bailoutRadius
+	if o.Trap != nil && n <= last {
+		point.trap(o.Trap, o.Z[n]+d.Complex128())
+	}
+	for ; d.E < MinExp && !d.IsZero(); n++ {
+		if n >= last {
+			return true, math.Inf(1)
+		}
+		d = d.Scale(2 * o.Z[n]).Add(d.Mul(d)).Add(dc)
+		zz := o.Z[n+1] + d.Complex128()
+		point.IterationCount = n + 1
+		v := norm(zz)
+
+		if v > bailout2 {
+			smooth(point, zz, n+1, 2)
+			return false, 0
+		}
+		if ref := norm(o.Z[n+1]); v < glitchTolerance*glitchTolerance*ref {
+			return true, math.Sqrt(v / ref)
+		}
+		if o.Trap != nil {
+			point.trap(o.Trap, zz)
+		}
+		if n+1 == maxIter {
+			point.Zn = zz
+			return false, 0
+		}
+	}
+	return o.IterateFrom(point, n, d.Complex128(), dc.Complex128(), maxIter, bailoutRadius)
+}
+
+// Series approximates the deltas of an orbit after Skip iterations as
+//
+//	d[Skip] = A*dc + B*dc^2 + C*dc^3 + r
+//
+// so the first Skip iterations can be left out for every pixel. The
+// truncation error r is at most R*|dc|^4.
+// https://mathr.co.uk/blog/2016-03-06_simpler_series_approximation.html
+type Series struct {
+	Skip    int
+	A, B, C floatexp.Complex
+	R       floatexp.Float
+}
+
+// Series returns the longest approximation whose truncation error is at
+// most maxError times a lower bound of |dz/dc| for the deltas up to
+// maxDelta, so it moves no pixel by more than about maxError. It never skips more than
+// limit iterations. The orbits of the pixels also provably stay within
+// |z| <= 2 during the skipped iterations, none of them escapes there.
+//
+// Putting d = P + r with the cubic P into d' = 2*Z*d + d^2 + dc leaves
+//
+//	r' = 2*Z*r + (B^2 + 2*A*C)*dc^4 + 2*B*C*dc^5 + C^2*dc^6 + 2*P*r + r^2
+//
+// and with |dc| <= t and |P| <= (|A| + |B|*t + |C|*t^2)*|dc| the bound
+//
+//	R' = 2*|Z|*R + |B^2 + 2*A*C| + 2*|B|*|C|*t + |C|^2*t^2 + 2*(|A| + |B|*t + |C|*t^2)*R*t + R^2*t^4
+func (o *Orbit) Series(maxDelta, maxError floatexp.Float, limit int) Series {
+	one := floatexp.NewComplex(1)
+	two := floatexp.New(2)
+	t := maxDelta
+	t2 := t.Mul(t)
+	t4 := t2.Mul(t2)
+	s := Series{A: one}
+	limit = min(limit, len(o.Z)-2)
+	for n := 0; n < limit; n++ {
+		z2 := 2 * o.Z[n]
+		a, b, c := s.A.Abs(), s.B.Abs(), s.C.Abs()
+		p := a.Add(b.Mul(t)).Add(c.Mul(t2))
+		next := Series{
+			Skip: n + 1,
+			A:    s.A.Scale(z2).Add(one),
+			B:    s.B.Scale(z2).Add(s.A.Mul(s.A)),
+			C:    s.C.Scale(z2).Add(s.A.Mul(s.B).Scale(2)),
+			R: floatexp.New(cmplx.Abs(z2)).Mul(s.R).
+				Add(s.B.Mul(s.B).Add(s.A.Mul(s.C).Scale(2)).Abs()).
+				Add(two.Mul(b).Mul(c).Mul(t)).
+				Add(c.Mul(c).Mul(t2)).
+				Add(two.Mul(p).Mul(s.R).Mul(t)).
+				Add(s.R.Mul(s.R).Mul(t4)),
+		}
+		err := next.R.Mul(t4)
+		// bounds of |z| and |dz/dc| of the pixels
+		reach := floatexp.New(cmplx.Abs(o.Z[n+1])).
+			Add(next.A.Abs().Add(next.B.Abs().Mul(t)).Add(next.C.Abs().Mul(t2)).Mul(t)).Add(err)
+		slope := next.A.Abs().Sub(two.Mul(next.B.Abs()).Mul(t)).Sub(floatexp.New(3).Mul(next.C.Abs()).Mul(t2))
+		if next.C.IsNaN() || math.IsNaN(next.R.M) ||
+			err.Cmp(maxError.Mul(slope)) > 0 || reach.Cmp(two) > 0 {
+			break
+		}
+		s = next
+	}
+	return s
+}
+
+// Delta returns d[s.Skip] for dc.
+func (s Series) Delta(dc floatexp.Complex) floatexp.Complex {
+	return s.C.Mul(dc).Add(s.B).Mul(dc).Add(s.A).Mul(dc)
+}
+
+// Derivative returns dz/dc at iteration s.Skip for dc, the derivative of
+// Delta.
+func (s Series) Derivative(dc floatexp.Complex) floatexp.Complex {
+	return s.C.Scale(3).Mul(dc).Add(s.B.Scale(2)).Mul(dc).Add(s.A)
+}
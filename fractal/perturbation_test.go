@@ -0,0 +1,49 @@
+package fractal
+
+import (
+	"math"
+	"math/big"
+	"math/cmplx"
+	"testing"
+
+	"github.com/jfhaecker/mandelgo/floatexp"
+)
+
+// The iterations skipped with a Series give the deltas of iterating them
+// within the error bound, and the same points.
+func TestSeries(t *testing.T) {
+	// the orbit of i is periodic and never escapes
+	orbit := NewOrbit(new(big.Float).SetPrec(128), new(big.Float).SetPrec(128).SetFloat64(1), 2000, 20)
+	const maxDelta, maxError = 1e-12, 1e-20
+	series := orbit.Series(floatexp.New(maxDelta), floatexp.New(maxError), 1999)
+	if series.Skip < 10 {
+		t.Fatalf("skips %v iterations", series.Skip)
+	}
+	for _, dc := range []complex128{maxDelta, -maxDelta * 1i, 0.6e-12 - 0.7e-12i, -3e-13 + 1e-13i} {
+		d := dc
+		for n := 0; n < series.Skip; n++ {
+			d = 2*orbit.Z[n]*d + d*d + dc
+		}
+		dce := floatexp.NewComplex(dc)
+		// float64 rounds a few ulps of d on top of the truncation error
+		bound := series.R.Float64() * math.Pow(cmplx.Abs(dc), 4)
+		if bound > maxError*series.A.Abs().Float64() {
+			t.Errorf("%v: truncation error up to %v after %v iterations", dc, bound, series.Skip)
+		}
+		if got := series.Delta(dce).Complex128(); cmplx.Abs(got-d) > bound+1e-12*cmplx.Abs(d) {
+			t.Errorf("%v: delta after %v iterations %v, want %v within %v", dc, series.Skip, got, d, bound)
+		}
+
+		var full, skipped Point
+		orbit.IterateFrom(&full, 0, dc, dc, 2000, 20)
+		orbit.IterateFrom(&skipped, series.Skip, series.Delta(dce).Complex128(), dc, 2000, 20)
+		if full.IterationCount == 2000 {
+			t.Errorf("%v did not escape", dc)
+		}
+		if full.IterationCount != skipped.IterationCount ||
+			math.Abs(full.NormIterationCount-skipped.NormIterationCount) > 1e-6 {
+			t.Errorf("%v: %v iterations (%v), skipping %v: %v (%v)", dc, full.IterationCount, full.NormIterationCount,
+				series.Skip, skipped.IterationCount, skipped.NormIterationCount)
+		}
+	}
+}
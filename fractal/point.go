@@ -0,0 +1,19 @@
+// Package fractal contains the iteration kernels.
+package fractal
+
+// Point is a single pixel of an image together with its escape data.
+type Point struct {
+	Z                  complex128
+	Zn                 complex128 // z after the last iteration
+	DZ                 complex128 // dz/dc after the last iteration if the kernel tracks it
+	Distance           float64    // distance estimate of escaped points with DZ, 0 if unknown
+	IterationCount     int
+	NormIterationCount float64
+	X, Y               int
+	Root               int // Newton only, see Newton.Iterate
+	Period             int // length of the attracting cycle if one was detected
+	// With a Trap, the smallest distance of the orbit to it and the z where
+	// the orbit came closest. Trap is +Inf if the orbit missed it.
+	Trap  float64
+	TrapZ complex128
+}
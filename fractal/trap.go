@@ -0,0 +1,140 @@
+package fractal
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"math/cmplx"
+)
+
+// Trap is a shape in the plane. Kernels with a trap record how close the
+// orbit of every point comes to it in Point.Trap and Point.TrapZ, orbit trap
+// coloring paints with that instead of the iteration count.
+// https://en.wikipedia.org/wiki/Orbit_trap
+type Trap interface {
+	// Distance returns how far z is from the trap, +Inf if it misses it.
+	Distance(z complex128) float64
+}
+
+// PointTrap traps orbits around a single point.
+type PointTrap struct {
+	Center complex128
+}
+
+func (t PointTrap) Distance(z complex128) float64 {
+	return cmplx.Abs(z - t.Center)
+}
+
+// LineTrap is the line through Center at Angle radians to the real axis.
+type LineTrap struct {
+	Center complex128
+	Angle  float64
+}
+
+func (t LineTrap) Distance(z complex128) float64 {
+	return math.Abs(imag((z - t.Center) * cmplx.Rect(1, -t.Angle)))
+}
+
+// CrossTrap is two perpendicular lines through Center, turned by Angle
+// radians from the axes.
+type CrossTrap struct {
+	Center complex128
+	Angle  float64
+}
+
+func (t CrossTrap) Distance(z complex128) float64 {
+	w := (z - t.Center) * cmplx.Rect(1, -t.Angle)
+	return math.Min(math.Abs(real(w)), math.Abs(imag(w)))
+}
+
+// CircleTrap is the circle of Radius around Center.
+type CircleTrap struct {
+	Center complex128
+	Radius float64
+}
+
+func (t CircleTrap) Distance(z complex128) float64 {
+	return math.Abs(cmplx.Abs(z-t.Center) - t.Radius)
+}
+
+// ImageTrap lays Image into the plane, centered at Center and Width wide.
+// An orbit is trapped by the first of its most opaque pixels, the coloring
+// takes its color with At. Transparent pixels do not trap.
+type ImageTrap struct {
+	Image  image.Image
+	Center complex128
+	Width  float64
+}
+
+// Distance returns 0 for opaque pixels, up to 1 for translucent ones.
+func (t *ImageTrap) Distance(z complex128) float64 {
+	x, y, ok := t.pixel(z)
+	if !ok {
+		return math.Inf(1)
+	}
+	_, _, _, a := t.Image.At(x, y).RGBA()
+	if a == 0 {
+		return math.Inf(1)
+	}
+	return 1 - float64(a)/0xffff
+}
+
+// At returns the color of the image at z, transparent outside of it.
+func (t *ImageTrap) At(z complex128) color.NRGBA {
+	x, y, ok := t.pixel(z)
+	if !ok {
+		return color.NRGBA{}
+	}
+	return color.NRGBAModel.Convert(t.Image.At(x, y)).(color.NRGBA)
+}
+
+func (t *ImageTrap) pixel(z complex128) (int, int, bool) {
+	b := t.Image.Bounds()
+	scale := float64(b.Dx()) / t.Width
+	d := z - t.Center
+	fx := real(d)*scale + float64(b.Dx())/2
+	fy := float64(b.Dy())/2 - imag(d)*scale
+	if fx < 0 || fy < 0 || fx >= float64(b.Dx()) || fy >= float64(b.Dy()) {
+		return 0, 0, false
+	}
+	return b.Min.X + int(fx), b.Min.Y + int(fy), true
+}
+
+// TrapNames lists the names understood by TrapByName.
+var TrapNames = []string{"point", "line", "cross", "circle", "image"}
+
+// TrapByName returns the trap with the given name around center. angle in
+// degrees turns lines and crosses, size is the radius of a circle or the
+// width of an image. img is only used by image.
+func TrapByName(name string, center complex128, angle, size float64, img image.Image) (Trap, error) {
+	rad := angle * math.Pi / 180
+	switch name {
+	case "point":
+		return PointTrap{center}, nil
+	case "line":
+		return LineTrap{center, rad}, nil
+	case "cross":
+		return CrossTrap{center, rad}, nil
+	case "circle":
+		return CircleTrap{center, size}, nil
+	case "image":
+		if img == nil {
+			return nil, errors.New("image trap without an image")
+		}
+		if size <= 0 {
+			return nil, fmt.Errorf("image trap width must be positive, not %v", size)
+		}
+		return &ImageTrap{img, center, size}, nil
+	}
+	return nil, fmt.Errorf("unknown trap %q", name)
+}
+
+// trap is the per-iteration hook of the kernels, it keeps the closest
+// approach of the orbit to t.
+func (p *Point) trap(t Trap, z complex128) {
+	if d := t.Distance(z); d < p.Trap {
+		p.Trap, p.TrapZ = d, z
+	}
+}
@@ -0,0 +1,115 @@
+package fractal
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/big"
+	"math/cmplx"
+	"testing"
+
+	"github.com/jfhaecker/mandelgo/floatexp"
+)
+
+func TestTrapDistance(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.NRGBA{255, 0, 0, 255}) // top left
+	img.Set(1, 0, color.NRGBA{0, 255, 0, 64})
+	for _, tc := range []struct {
+		trap Trap
+		z    complex128
+		want float64
+	}{
+		{PointTrap{1i}, 3 + 1i, 3},
+		{LineTrap{1, 0}, 5 - 2i, 2},
+		{LineTrap{0, math.Pi / 4}, 1, math.Sqrt2 / 2},
+		{CrossTrap{1 + 1i, 0}, 4 + 3i, 2},
+		{CrossTrap{0, math.Pi / 4}, 2 + 1i, math.Sqrt2 / 2},
+		{CircleTrap{0, 2}, 0.5, 1.5},
+		{CircleTrap{0, 2}, 3i, 1},
+		{&ImageTrap{img, 0, 2}, -0.5 + 0.5i, 0},
+		{&ImageTrap{img, 0, 2}, 0.5 + 0.5i, 1 - 64.0/255},
+		{&ImageTrap{img, 0, 2}, 0.5 - 0.5i, math.Inf(1)}, // transparent
+		{&ImageTrap{img, 0, 2}, 2, math.Inf(1)},          // outside
+	} {
+		if got := tc.trap.Distance(tc.z); math.Abs(got-tc.want) > 1e-12 && got != tc.want {
+			t.Errorf("%T %v: distance %v, want %v", tc.trap, tc.z, got, tc.want)
+		}
+	}
+	if c := (&ImageTrap{img, 0, 2}).At(-0.5 + 0.5i); c != (color.NRGBA{255, 0, 0, 255}) {
+		t.Errorf("image color %v, want red", c)
+	}
+}
+
+// The trap records the closest z of the orbit and leaves the escape data
+// alone.
+func TestTrapHook(t *testing.T) {
+	trap := PointTrap{0.3}
+	for _, julia := range []bool{false, true} {
+		e := Escape{Formula: Quadratic{}, BailoutRadius: 20, Julia: julia, C: -0.4 + 0.6i}
+		for _, z := range []complex128{-0.75 + 0.1i, 0.3 + 0.5i, -1.8, 0.4 - 0.3i} {
+			plain := *e.Iterate(&Point{Z: z}, 500)
+			e.Trap = trap
+			trapped := *e.Iterate(&Point{Z: z}, 500)
+			e.Trap = nil
+
+			c, zz, best, bestZ := z, z, math.Inf(1), complex128(0)
+			if julia {
+				c = e.C
+			}
+			for i := 0; i < 500 && cmplx.Abs(zz) <= 20; i++ {
+				if d := trap.Distance(zz); d < best {
+					best, bestZ = d, zz
+				}
+				zz = zz*zz + c
+			}
+			if trapped.Trap != best || trapped.TrapZ != bestZ {
+				t.Errorf("%v (julia %v): trap %v at %v, want %v at %v", z, julia, trapped.Trap, trapped.TrapZ, best, bestZ)
+			}
+			if trapped.IterationCount != plain.IterationCount || trapped.NormIterationCount != plain.NormIterationCount {
+				t.Errorf("%v (julia %v): the trap changed the escape data", z, julia)
+			}
+		}
+	}
+}
+
+// Perturbation and the big.Float fallback see the same orbits as the
+// float64 kernel.
+func TestTrapPerturbation(t *testing.T) {
+	trap := CircleTrap{0.1i, 0.25}
+	e := Escape{Formula: Quadratic{}, BailoutRadius: 20, Trap: trap}
+	bigOf := func(c complex128) (*big.Float, *big.Float) {
+		return new(big.Float).SetPrec(128).SetFloat64(real(c)), new(big.Float).SetPrec(128).SetFloat64(imag(c))
+	}
+	// the cusp never escapes, the points right of it do
+	c0 := complex(0.25, 0)
+	orbit := NewOrbit(new(big.Float).SetPrec(128).SetFloat64(real(c0)), new(big.Float).SetPrec(128).SetFloat64(imag(c0)), 500, 20)
+	orbit.Trap = trap
+	for _, dc := range []complex128{1e-3, 0.05, 0.1 + 0.01i} {
+		want := *e.Iterate(&Point{Z: c0 + dc}, 500)
+		var got Point
+		if glitched, _ := orbit.Iterate(&got, dc, 500, 20); glitched {
+			t.Fatalf("%v glitched", dc)
+		}
+		if got.IterationCount != want.IterationCount || math.Abs(got.Trap-want.Trap) > 1e-9 {
+			t.Errorf("perturbation %v: trap %v after %v, want %v after %v", dc, got.Trap, got.IterationCount, want.Trap, want.IterationCount)
+		}
+		cx, cy := bigOf(c0 + dc)
+		got = Point{}
+		BigMandelbrot(&got, cx, cy, 500, 20, trap)
+		if math.Abs(got.Trap-want.Trap) > 1e-9 {
+			t.Errorf("big %v: trap %v, want %v", dc, got.Trap, want.Trap)
+		}
+	}
+
+	// the orbit of the cusp creeps up to 1/2
+	orbit.Trap = PointTrap{0.5}
+	dc := floatexp.NewComplex(1e-300 + 1e-300i)
+	var exp, plain Point
+	exp.Trap, plain.Trap = math.Inf(1), math.Inf(1)
+	orbit.IterateExp(&exp, 0, dc, dc, 500, 20)
+	orbit.IterateFrom(&plain, 0, dc.Complex128(), dc.Complex128(), 500, 20)
+	if exp.Trap != plain.Trap || exp.TrapZ != plain.TrapZ {
+		t.Errorf("IterateExp: trap %v at %v, want %v at %v", exp.Trap, exp.TrapZ, plain.Trap, plain.TrapZ)
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/jfhaecker/mandelgo
+
+go 1.22
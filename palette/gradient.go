@@ -0,0 +1,309 @@
+package palette
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+)
+
+// Stop is a color at a position of a Gradient.
+type Stop struct {
+	Pos   float64 // in [0, 1]
+	Color color.RGBA
+}
+
+// Gradient blends between colors at positions in [0, 1].
+type Gradient struct {
+	Stops  []Stop // sorted by Pos
+	Space  Space
+	Easing Easing
+	// Cyclic gradients blend from the last stop back to the first.
+	Cyclic bool
+	// Period is the number of iterations of one pass through the gradient,
+	// 0 means one per stop.
+	Period float64
+}
+
+// NewGradient sorts stops by position, positions are clamped to [0, 1].
+func NewGradient(stops []Stop, cyclic bool, period float64) *Gradient {
+	s := make([]Stop, len(stops))
+	for i, st := range stops {
+		s[i] = Stop{math.Min(1, math.Max(0, st.Pos)), st.Color}
+	}
+	sort.SliceStable(s, func(i, j int) bool { return s[i].Pos < s[j].Pos })
+	return &Gradient{Stops: s, Cyclic: cyclic, Period: period}
+}
+
+// Gradient spreads the colors of p evenly over a cyclic gradient with one
+// iteration per color, which paints like the palette itself.
+func (p Palette) Gradient() *Gradient {
+	stops := make([]Stop, len(p))
+	for i, c := range p {
+		stops[i] = Stop{float64(i) / float64(len(p)), c}
+	}
+	return &Gradient{Stops: stops, Cyclic: true, Period: float64(len(p))}
+}
+
+// At returns the color at t. Cyclic gradients wrap t around, the others
+// clamp it to [0, 1]. NaN and -Inf give the first color, +Inf the last.
+func (g *Gradient) At(t float64) color.RGBA {
+	stops := g.Stops
+	first, last := stops[0], stops[len(stops)-1]
+	switch {
+	case math.IsNaN(t) || math.IsInf(t, -1):
+		return first.Color
+	case math.IsInf(t, 1):
+		return last.Color
+	}
+	if g.Cyclic {
+		t -= math.Floor(t)
+	}
+	if t < first.Pos || t >= last.Pos {
+		if !g.Cyclic {
+			if t < first.Pos {
+				return first.Color
+			}
+			return last.Color
+		}
+		// between the last stop and the first one of the next cycle
+		span := first.Pos + 1 - last.Pos
+		d := t - last.Pos
+		if d < 0 {
+			d += 1
+		}
+		if span == 0 {
+			return first.Color
+		}
+		return g.blend(last.Color, first.Color, d/span)
+	}
+	i := sort.Search(len(stops), func(i int) bool { return stops[i].Pos > t })
+	a, b := stops[i-1], stops[i]
+	return g.blend(a.Color, b.Color, (t-a.Pos)/(b.Pos-a.Pos))
+}
+
+// Iteration returns the color of the smooth iteration count n.
+func (g *Gradient) Iteration(n float64) color.RGBA {
+	period := g.Period
+	if period <= 0 {
+		period = float64(len(g.Stops))
+	}
+	t := n / period
+	if math.IsInf(t, 0) {
+		return g.At(t)
+	}
+	return g.At(t - math.Floor(t))
+}
+
+func (g *Gradient) blend(c1, c2 color.RGBA, t float64) color.RGBA {
+	return g.Space.Interpolate(c1, c2, g.Easing.Ease(t))
+}
+
+// Space is a color space gradients are interpolated in.
+type Space int
+
+const (
+	// InSRGB blends the channel values, the classic look.
+	InSRGB Space = iota
+	// InLinearRGB blends light intensities, it avoids the dark band between
+	// saturated colors.
+	InLinearRGB
+	// InOKLab is perceptually uniform, lightness changes evenly.
+	// https://bottosson.github.io/posts/oklab/
+	InOKLab
+	// InLCh is OKLab in polar coordinates, hue goes the short way around.
+	InLCh
+	// InHSV goes around the hue circle the short way.
+	InHSV
+)
+
+// SpaceNames lists the names understood by SpaceByName.
+var SpaceNames = []string{"srgb", "linear", "oklab", "lch", "hsv"}
+
+func SpaceByName(name string) (Space, error) {
+	for i, n := range SpaceNames {
+		if n == name {
+			return Space(i), nil
+		}
+	}
+	return InSRGB, fmt.Errorf("unknown color space %q", name)
+}
+
+func (s Space) String() string {
+	if s < InSRGB || s > InHSV {
+		return fmt.Sprintf("Space(%d)", int(s))
+	}
+	return SpaceNames[s]
+}
+
+// Interpolate blends c1 and c2 in s, t runs from 0 to 1.
+func (s Space) Interpolate(c1, c2 color.RGBA, t float64) color.RGBA {
+	switch s {
+	case InLinearRGB:
+		a, b := linearRGB(c1), linearRGB(c2)
+		return fromLinearRGB(lerp3(a, b, t))
+	case InOKLab:
+		a, b := toOKLab(linearRGB(c1)), toOKLab(linearRGB(c2))
+		return fromLinearRGB(fromOKLab(lerp3(a, b, t)))
+	case InLCh:
+		a, b := toLCh(toOKLab(linearRGB(c1))), toLCh(toOKLab(linearRGB(c2)))
+		a[2], b[2] = hues(a[2], b[2], a[1], b[1])
+		return fromLinearRGB(fromOKLab(fromLCh(lerp3(a, b, t))))
+	case InHSV:
+		a, b := toHSV(c1), toHSV(c2)
+		a[0], b[0] = hues(a[0]*360, b[0]*360, a[1], b[1])
+		hsv := lerp3(a, b, t)
+		return HSV(hsv[0]/360, hsv[1], hsv[2])
+	}
+	return Interpolate(c1, c2, t)
+}
+
+// Easing shapes the blend between two stops.
+type Easing int
+
+const (
+	Linear Easing = iota
+	EaseIn
+	EaseOut
+	// EaseInOut is smoothstep, the colors rest at the stops.
+	EaseInOut
+)
+
+// EasingNames lists the names understood by EasingByName.
+var EasingNames = []string{"linear", "ease-in", "ease-out", "ease-in-out"}
+
+func EasingByName(name string) (Easing, error) {
+	for i, n := range EasingNames {
+		if n == name {
+			return Easing(i), nil
+		}
+	}
+	return Linear, fmt.Errorf("unknown easing %q", name)
+}
+
+func (e Easing) String() string {
+	if e < Linear || e > EaseInOut {
+		return fmt.Sprintf("Easing(%d)", int(e))
+	}
+	return EasingNames[e]
+}
+
+// Ease maps t in [0, 1] to [0, 1].
+func (e Easing) Ease(t float64) float64 {
+	switch e {
+	case EaseIn:
+		return t * t
+	case EaseOut:
+		return t * (2 - t)
+	case EaseInOut:
+		return t * t * (3 - 2*t)
+	}
+	return t
+}
+
+func lerp3(a, b [3]float64, t float64) [3]float64 {
+	return [3]float64{a[0] + (b[0]-a[0])*t, a[1] + (b[1]-a[1])*t, a[2] + (b[2]-a[2])*t}
+}
+
+// hues moves the hue b by a full turn so that blending from a goes the short
+// way. A hue without chroma is meaningless, it takes the other one.
+func hues(a, b, chromaA, chromaB float64) (float64, float64) {
+	const gray = 1e-6
+	switch {
+	case chromaA < gray:
+		a = b
+	case chromaB < gray:
+		b = a
+	}
+	switch {
+	case b-a > 180:
+		b -= 360
+	case a-b > 180:
+		b += 360
+	}
+	return a, b
+}
+
+// https://en.wikipedia.org/wiki/SRGB#Transfer_function_(%22gamma%22)
+func linearRGB(c color.RGBA) [3]float64 {
+	f := func(v uint8) float64 {
+		x := float64(v) / 255
+		if x <= 0.04045 {
+			return x / 12.92
+		}
+		return math.Pow((x+0.055)/1.055, 2.4)
+	}
+	return [3]float64{f(c.R), f(c.G), f(c.B)}
+}
+
+func fromLinearRGB(rgb [3]float64) color.RGBA {
+	f := func(x float64) uint8 {
+		x = math.Min(1, math.Max(0, x))
+		if x <= 0.0031308 {
+			x *= 12.92
+		} else {
+			x = 1.055*math.Pow(x, 1/2.4) - 0.055
+		}
+		return uint8(math.Round(x * 255))
+	}
+	return color.RGBA{f(rgb[0]), f(rgb[1]), f(rgb[2]), 255}
+}
+
+func toOKLab(rgb [3]float64) [3]float64 {
+	r, g, b := rgb[0], rgb[1], rgb[2]
+	l := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*b)
+	m := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*b)
+	s := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*b)
+	return [3]float64{
+		0.2104542553*l + 0.7936177850*m - 0.0040720468*s,
+		1.9779984951*l - 2.4285922050*m + 0.4505937099*s,
+		0.0259040371*l + 0.7827717662*m - 0.8086757660*s,
+	}
+}
+
+func fromOKLab(lab [3]float64) [3]float64 {
+	L, a, b := lab[0], lab[1], lab[2]
+	l := L + 0.3963377774*a + 0.2158037573*b
+	m := L - 0.1055613458*a - 0.0638541728*b
+	s := L - 0.0894841775*a - 1.2914855480*b
+	l, m, s = l*l*l, m*m*m, s*s*s
+	return [3]float64{
+		4.0767416621*l - 3.3077115913*m + 0.2309699292*s,
+		-1.2684380046*l + 2.6097574011*m - 0.3413193965*s,
+		-0.0041960863*l - 0.7034186147*m + 1.7076147010*s,
+	}
+}
+
+// toLCh returns lightness, chroma and hue in degrees.
+func toLCh(lab [3]float64) [3]float64 {
+	h := math.Atan2(lab[2], lab[1]) * 180 / math.Pi
+	return [3]float64{lab[0], math.Hypot(lab[1], lab[2]), h}
+}
+
+func fromLCh(lch [3]float64) [3]float64 {
+	s, c := math.Sincos(lch[2] * math.Pi / 180)
+	return [3]float64{lch[0], lch[1] * c, lch[1] * s}
+}
+
+// toHSV returns hue, saturation and value in [0, 1].
+func toHSV(c color.RGBA) [3]float64 {
+	r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	v := math.Max(r, math.Max(g, b))
+	d := v - math.Min(r, math.Min(g, b))
+	if d == 0 {
+		return [3]float64{0, 0, v}
+	}
+	var h float64
+	switch v {
+	case r:
+		h = math.Mod((g-b)/d, 6)
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	if h < 0 {
+		h += 6
+	}
+	return [3]float64{h / 6, d / v, v}
+}
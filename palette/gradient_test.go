@@ -0,0 +1,100 @@
+package palette
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestInterpolateDarker(t *testing.T) {
+	c := Interpolate(color.RGBA{200, 100, 50, 255}, color.RGBA{0, 0, 0, 255}, 0.5)
+	if c != (color.RGBA{100, 50, 25, 255}) {
+		t.Errorf("got %v, want {100 50 25 255}", c)
+	}
+}
+
+func TestPaletteGradient(t *testing.T) {
+	g := Quake.Gradient()
+	for i := range Quake {
+		n := float64(i) + 0.25
+		want := Interpolate(Quake.At(i), Quake.At(i+1), 0.25)
+		if got := g.Iteration(n); got != want {
+			t.Fatalf("iteration %v: got %v, want %v", n, got, want)
+		}
+	}
+}
+
+func TestGradientSpaces(t *testing.T) {
+	red, blue := color.RGBA{255, 0, 0, 255}, color.RGBA{0, 0, 255, 255}
+	for space := InSRGB; space <= InHSV; space++ {
+		for easing := Linear; easing <= EaseInOut; easing++ {
+			g := NewGradient([]Stop{{1, blue}, {0, red}}, false, 0)
+			g.Space, g.Easing = space, easing
+			if g.At(-1) != red || g.At(0) != red || g.At(1) != blue || g.At(2) != blue {
+				t.Errorf("%v %v: ends %v %v", space, easing, g.At(0), g.At(1))
+			}
+		}
+	}
+	mid := func(space Space) color.RGBA {
+		return space.Interpolate(red, blue, 0.5)
+	}
+	if c := mid(InLinearRGB); c != (color.RGBA{188, 0, 188, 255}) {
+		t.Errorf("linear: %v", c)
+	}
+	// the hue goes through magenta, not through green
+	if c := mid(InHSV); c != (color.RGBA{255, 0, 255, 255}) {
+		t.Errorf("hsv: %v", c)
+	}
+	if c := mid(InLCh); c.G > c.R || c.G > c.B {
+		t.Errorf("lch: %v", c)
+	}
+	gray := color.RGBA{119, 119, 119, 255}
+	if c := InOKLab.Interpolate(gray, gray, 0.3); c != gray {
+		t.Errorf("oklab round trip: %v", c)
+	}
+}
+
+func TestGradientCyclic(t *testing.T) {
+	black, white := color.RGBA{0, 0, 0, 255}, color.RGBA{255, 255, 255, 255}
+	g := NewGradient([]Stop{{0.25, black}, {0.75, white}}, true, 100)
+	for _, tc := range []struct {
+		t    float64
+		want color.RGBA
+	}{
+		{0.25, black},
+		{0.5, color.RGBA{127, 127, 127, 255}},
+		{0.75, white},
+		{1.0, color.RGBA{128, 128, 128, 255}},
+		{1.25, black},
+	} {
+		if got := g.At(tc.t); got != tc.want {
+			t.Errorf("At(%v) = %v, want %v", tc.t, got, tc.want)
+		}
+	}
+	if got := g.Iteration(175); got != white {
+		t.Errorf("Iteration(175) = %v, want white", got)
+	}
+}
+
+func TestGradientNotFinite(t *testing.T) {
+	g := Quake.Gradient()
+	first, last := g.Stops[0].Color, g.Stops[len(g.Stops)-1].Color
+	for _, cyclic := range []bool{true, false} {
+		g.Cyclic = cyclic
+		for _, tc := range []struct {
+			t    float64
+			want color.RGBA
+		}{
+			{math.NaN(), first},
+			{math.Inf(-1), first},
+			{math.Inf(1), last},
+		} {
+			if got := g.At(tc.t); got != tc.want {
+				t.Errorf("cyclic %v: At(%v) = %v, want %v", cyclic, tc.t, got, tc.want)
+			}
+			if got := g.Iteration(tc.t); got != tc.want {
+				t.Errorf("cyclic %v: Iteration(%v) = %v, want %v", cyclic, tc.t, got, tc.want)
+			}
+		}
+	}
+}
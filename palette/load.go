@@ -0,0 +1,370 @@
+package palette
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Named is a gradient read from a file together with its name.
+type Named struct {
+	Name     string
+	Gradient *Gradient
+}
+
+// Load reads a palette file, the format is picked by the extension: GIMP
+// .gpl, Fractint .map, UltraFractal .ugr or CSS gradient stops in .css. The
+// palettes are named after the file unless the format has names. Palettes
+// of single colors become gradients with one iteration per color.
+func Load(fileName string) ([]Named, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	ext := strings.ToLower(filepath.Ext(fileName))
+	name := strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName))
+	var palettes []Named
+	switch ext {
+	case ".gpl":
+		var p Palette
+		name, p, err = ParseGPL(bytes.NewReader(data), name)
+		palettes = []Named{{name, p.Gradient()}}
+	case ".map":
+		var p Palette
+		p, err = ParseMap(bytes.NewReader(data))
+		palettes = []Named{{name, p.Gradient()}}
+	case ".ugr":
+		palettes, err = ParseUGR(bytes.NewReader(data))
+	case ".css":
+		var g *Gradient
+		g, err = ParseCSS(string(data))
+		palettes = []Named{{name, g}}
+	default:
+		err = errors.New("unknown palette format, use .gpl, .map, .ugr or .css")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", fileName, err)
+	}
+	return palettes, nil
+}
+
+// ParseGPL reads a GIMP palette and returns its name and colors. Its Name
+// line overrides name.
+// https://developer.gimp.org/core/standards/gpl/
+func ParseGPL(r io.Reader, name string) (string, Palette, error) {
+	s := bufio.NewScanner(r)
+	if !s.Scan() || strings.TrimSpace(s.Text()) != "GIMP Palette" {
+		return "", nil, errors.New("gpl: missing GIMP Palette header")
+	}
+	var p Palette
+	for line := 2; s.Scan(); line++ {
+		text := strings.TrimSpace(s.Text())
+		switch {
+		case text == "" || strings.HasPrefix(text, "#"):
+		case strings.HasPrefix(text, "Name:"):
+			name = strings.TrimSpace(strings.TrimPrefix(text, "Name:"))
+		case strings.HasPrefix(text, "Columns:"):
+		default:
+			c, err := parseRGB(strings.Fields(text))
+			if err != nil {
+				return "", nil, fmt.Errorf("gpl: line %v: %w", line, err)
+			}
+			p = append(p, c)
+		}
+	}
+	if len(p) == 0 {
+		return "", nil, errors.New("gpl: no colors")
+	}
+	return name, p, s.Err()
+}
+
+// ParseMap reads a Fractint palette, one "red green blue" line per color.
+// Anything after the three numbers is a comment.
+func ParseMap(r io.Reader) (Palette, error) {
+	s := bufio.NewScanner(r)
+	var p Palette
+	for line := 1; s.Scan(); line++ {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		c, err := parseRGB(fields)
+		if err != nil {
+			return nil, fmt.Errorf("map: line %v: %w", line, err)
+		}
+		p = append(p, c)
+	}
+	if len(p) == 0 {
+		return nil, errors.New("map: no colors")
+	}
+	return p, s.Err()
+}
+
+func parseRGB(fields []string) (color.RGBA, error) {
+	if len(fields) < 3 {
+		return color.RGBA{}, errors.New("need red, green and blue")
+	}
+	var rgb [3]uint8
+	for i := range rgb {
+		v, err := strconv.ParseUint(fields[i], 10, 8)
+		if err != nil {
+			return color.RGBA{}, fmt.Errorf("bad color value %q", fields[i])
+		}
+		rgb[i] = uint8(v)
+	}
+	return color.RGBA{rgb[0], rgb[1], rgb[2], 255}, nil
+}
+
+// ugrSize is the number of positions of an UltraFractal gradient, one
+// iteration each.
+const ugrSize = 400
+
+// ParseUGR reads all gradients of an UltraFractal gradient file:
+//
+//	name {
+//	gradient:
+//	  title="name" smooth=no
+//	  index=0 color=16777215
+//	  index=200 color=0
+//	}
+//
+// Colors are 0xBBGGRR, the indices run from 0 to 399 and wrap around.
+func ParseUGR(r io.Reader) ([]Named, error) {
+	var palettes []Named
+	var name string
+	var stops []Stop
+	inGradient := false
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		text := strings.TrimSpace(s.Text())
+		switch {
+		case strings.HasSuffix(text, "{"):
+			name, stops, inGradient = strings.TrimSpace(strings.TrimSuffix(text, "{")), nil, false
+		case text == "}":
+			if len(stops) == 0 {
+				return nil, fmt.Errorf("ugr: line %v: gradient %q has no colors", line, name)
+			}
+			palettes = append(palettes, Named{name, NewGradient(stops, true, ugrSize)})
+		case strings.HasSuffix(text, ":"):
+			inGradient = text == "gradient:"
+		case inGradient:
+			values := map[string]string{}
+			for _, f := range strings.Fields(text) {
+				if k, v, ok := strings.Cut(f, "="); ok {
+					values[k] = v
+				}
+			}
+			if _, ok := values["index"]; !ok {
+				continue
+			}
+			index, err1 := strconv.Atoi(values["index"])
+			bgr, err2 := strconv.ParseUint(values["color"], 10, 32)
+			if err1 != nil || err2 != nil {
+				return nil, fmt.Errorf("ugr: line %v: bad index or color", line)
+			}
+			pos := float64(((index%ugrSize)+ugrSize)%ugrSize) / ugrSize
+			stops = append(stops, Stop{pos, color.RGBA{uint8(bgr), uint8(bgr >> 8), uint8(bgr >> 16), 255}})
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(palettes) == 0 {
+		return nil, errors.New("ugr: no gradients")
+	}
+	return palettes, nil
+}
+
+// cssPeriod is the number of iterations of one pass through a CSS gradient.
+const cssPeriod = 256
+
+// cssSpaces maps CSS color interpolation methods to spaces.
+// https://developer.mozilla.org/en-US/docs/Web/CSS/color-interpolation-method
+var cssSpaces = map[string]Space{
+	"srgb":        InSRGB,
+	"srgb-linear": InLinearRGB,
+	"oklab":       InOKLab,
+	"oklch":       InLCh,
+}
+
+// ParseCSS reads the color stops of the first CSS gradient in s, like
+//
+//	linear-gradient(to right in oklab, #000 0%, rgb(255, 128, 0) 40%, white)
+//
+// Stops without a position are spread evenly between their neighbors.
+// https://developer.mozilla.org/en-US/docs/Web/CSS/gradient/linear-gradient
+func ParseCSS(s string) (*Gradient, error) {
+	start := strings.Index(s, "gradient(")
+	if start < 0 {
+		return nil, errors.New("css: no gradient")
+	}
+	args, err := splitArgs(s[start+len("gradient("):])
+	if err != nil {
+		return nil, err
+	}
+	space := InSRGB
+	if len(args) > 0 && !isColor(args[0]) {
+		// direction or shape, then the interpolation method
+		fields := strings.Fields(args[0])
+		for i := 0; i+1 < len(fields); i++ {
+			if fields[i] == "in" {
+				sp, ok := cssSpaces[strings.ToLower(fields[i+1])]
+				if !ok {
+					return nil, fmt.Errorf("css: unsupported color space %q", fields[i+1])
+				}
+				space = sp
+			}
+		}
+		args = args[1:]
+	}
+	if len(args) < 2 {
+		return nil, errors.New("css: a gradient needs at least two stops")
+	}
+	stops := make([]Stop, len(args))
+	known := make([]bool, len(args))
+	for i, arg := range args {
+		c, rest, err := parseCSSColor(arg)
+		if err != nil {
+			return nil, err
+		}
+		stops[i].Color = c
+		if rest = strings.TrimSpace(rest); rest != "" {
+			pct, ok := strings.CutSuffix(rest, "%")
+			pos, err := strconv.ParseFloat(pct, 64)
+			if !ok || err != nil {
+				return nil, fmt.Errorf("css: bad stop position %q", rest)
+			}
+			stops[i].Pos, known[i] = pos/100, true
+		}
+	}
+	if !known[0] {
+		stops[0].Pos, known[0] = 0, true
+	}
+	if last := len(stops) - 1; !known[last] {
+		stops[last].Pos, known[last] = 1, true
+	}
+	for i := 1; i < len(stops); i++ {
+		if known[i] {
+			continue
+		}
+		j := i
+		for !known[j] {
+			j++
+		}
+		for k := i; k < j; k++ {
+			t := float64(k-i+1) / float64(j-i+1)
+			stops[k].Pos = stops[i-1].Pos + t*(stops[j].Pos-stops[i-1].Pos)
+		}
+		i = j
+	}
+	// a position before an earlier one is moved to it
+	for i := 1; i < len(stops); i++ {
+		stops[i].Pos = math.Max(stops[i].Pos, stops[i-1].Pos)
+	}
+	g := NewGradient(stops, false, cssPeriod)
+	g.Space = space
+	return g, nil
+}
+
+// splitArgs splits the arguments of a CSS function at top level commas up to
+// the closing parenthesis.
+func splitArgs(s string) ([]string, error) {
+	var args []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				return append(args, strings.TrimSpace(s[start:i])), nil
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return nil, errors.New("css: unterminated gradient")
+}
+
+var cssColors = map[string]color.RGBA{
+	"black":   {0, 0, 0, 255},
+	"white":   {255, 255, 255, 255},
+	"red":     {255, 0, 0, 255},
+	"lime":    {0, 255, 0, 255},
+	"green":   {0, 128, 0, 255},
+	"blue":    {0, 0, 255, 255},
+	"yellow":  {255, 255, 0, 255},
+	"cyan":    {0, 255, 255, 255},
+	"aqua":    {0, 255, 255, 255},
+	"magenta": {255, 0, 255, 255},
+	"fuchsia": {255, 0, 255, 255},
+	"orange":  {255, 165, 0, 255},
+	"purple":  {128, 0, 128, 255},
+	"navy":    {0, 0, 128, 255},
+	"teal":    {0, 128, 128, 255},
+	"maroon":  {128, 0, 0, 255},
+	"gray":    {128, 128, 128, 255},
+	"grey":    {128, 128, 128, 255},
+	"silver":  {192, 192, 192, 255},
+	"gold":    {255, 215, 0, 255},
+}
+
+// ParseColor parses a CSS color: #rgb, #rrggbb, rgb(r, g, b) or a name like
+// navy.
+func ParseColor(s string) (color.RGBA, error) {
+	c, rest, err := parseCSSColor(s)
+	if err == nil && strings.TrimSpace(rest) != "" {
+		err = fmt.Errorf("css: bad color %q", s)
+	}
+	return c, err
+}
+
+func isColor(arg string) bool {
+	_, _, err := parseCSSColor(arg)
+	return err == nil
+}
+
+// parseCSSColor parses the color at the start of a stop and returns the rest.
+func parseCSSColor(arg string) (color.RGBA, string, error) {
+	arg = strings.TrimSpace(arg)
+	word, rest, _ := strings.Cut(arg, " ")
+	switch {
+	case strings.HasPrefix(arg, "#"):
+		hex := word[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if len(hex) != 6 || err != nil {
+			return color.RGBA{}, "", fmt.Errorf("css: bad color %q", word)
+		}
+		return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 255}, rest, nil
+	case strings.HasPrefix(arg, "rgb"):
+		open, end := strings.IndexByte(arg, '('), strings.IndexByte(arg, ')')
+		if open < 0 || end < open {
+			return color.RGBA{}, "", fmt.Errorf("css: bad color %q", arg)
+		}
+		fields := strings.FieldsFunc(arg[open+1:end], func(r rune) bool { return r == ',' || r == ' ' || r == '/' })
+		c, err := parseRGB(fields)
+		if err != nil {
+			return color.RGBA{}, "", fmt.Errorf("css: %q: %w", arg, err)
+		}
+		return c, arg[end+1:], nil
+	}
+	if c, ok := cssColors[strings.ToLower(word)]; ok {
+		return c, rest, nil
+	}
+	return color.RGBA{}, "", fmt.Errorf("css: unknown color %q", word)
+}
@@ -0,0 +1,130 @@
+package palette
+
+import (
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseGPL(t *testing.T) {
+	name, p, err := ParseGPL(strings.NewReader("GIMP Palette\nName: Fire\nColumns: 4\n# comment\n255   0   0\tred\n  0 128 255 sky\n"), "file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Palette{{255, 0, 0, 255}, {0, 128, 255, 255}}
+	if name != "Fire" || !equal(p, want) {
+		t.Errorf("got %v %v, want Fire %v", name, p, want)
+	}
+	if _, _, err := ParseGPL(strings.NewReader("255 0 0\n"), "x"); err == nil {
+		t.Error("palette without header accepted")
+	}
+}
+
+func TestParseMap(t *testing.T) {
+	p, err := ParseMap(strings.NewReader("0 0 0 black\n\n10 20 30\n255 255 255 white and more\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Palette{{0, 0, 0, 255}, {10, 20, 30, 255}, {255, 255, 255, 255}}
+	if !equal(p, want) {
+		t.Errorf("got %v, want %v", p, want)
+	}
+	if _, err := ParseMap(strings.NewReader("0 0 256\n")); err == nil {
+		t.Error("color value 256 accepted")
+	}
+}
+
+func TestParseUGR(t *testing.T) {
+	ugr := `first {
+gradient:
+  title="first" smooth=no
+  index=0 color=255
+  index=200 color=16711680
+opacity:
+  smooth=no index=0 opacity=255
+}
+second {
+gradient:
+  index=100 color=65280
+}
+`
+	palettes, err := ParseUGR(strings.NewReader(ugr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(palettes) != 2 || palettes[0].Name != "first" || palettes[1].Name != "second" {
+		t.Fatalf("got %v palettes", len(palettes))
+	}
+	g := palettes[0].Gradient
+	if g.Period != ugrSize || g.Iteration(0) != (color.RGBA{255, 0, 0, 255}) || g.Iteration(200) != (color.RGBA{0, 0, 255, 255}) {
+		t.Errorf("stops at %v and %v, want red and blue", g.Iteration(0), g.Iteration(200))
+	}
+	// halfway back from blue to red
+	if c := g.Iteration(300); c != (color.RGBA{127, 0, 128, 255}) {
+		t.Errorf("wrap around at %v", c)
+	}
+	if g := palettes[1].Gradient; g.Iteration(0) != (color.RGBA{0, 255, 0, 255}) || g.Iteration(399) != g.Iteration(0) {
+		t.Errorf("single stop gradient %v %v", g.Iteration(0), g.Iteration(399))
+	}
+}
+
+func TestParseCSS(t *testing.T) {
+	g, err := ParseCSS(".sky { background: linear-gradient(to right, #000 0%, rgb(255, 0, 0), white 100%); }")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Cyclic || g.Space != InSRGB || g.At(0) != (color.RGBA{0, 0, 0, 255}) || g.At(1) != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("ends are %v and %v", g.At(0), g.At(1))
+	}
+	// the middle stop gets 50%
+	if mid := g.At(0.5); mid != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("middle is %v, want red", mid)
+	}
+	g, err = ParseCSS("linear-gradient(in oklch, red, blue)")
+	if err != nil || g.Space != InLCh {
+		t.Errorf("interpolation method: %v %v", g, err)
+	}
+	for _, bad := range []string{"no gradient here", "linear-gradient(red)", "linear-gradient(red, nocolor)", "linear-gradient(red, blue", "linear-gradient(in lab, red, blue)"} {
+		if _, err := ParseCSS(bad); err == nil {
+			t.Errorf("%q accepted", bad)
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	for _, name := range []string{"quake", "quake2"} {
+		if _, ok := Default.Lookup(name); !ok {
+			t.Errorf("built-in %v not registered", name)
+		}
+	}
+	dir := t.TempDir()
+	file := filepath.Join(dir, "mine.map")
+	if err := os.WriteFile(file, []byte("1 2 3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r := NewRegistry()
+	names, err := r.LoadFile(file)
+	if err != nil || len(names) != 1 || names[0] != "mine" {
+		t.Fatalf("LoadFile: %v %v", names, err)
+	}
+	if got := r.Names(); len(got) != 1 || got[0] != "mine" {
+		t.Errorf("names %v", got)
+	}
+	if _, err := r.LoadFile(filepath.Join(dir, "mine.txt")); err == nil {
+		t.Error("unknown format accepted")
+	}
+}
+
+func equal(a, b Palette) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
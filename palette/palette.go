@@ -0,0 +1,26 @@
+// Package palette contains the color palettes used to paint escape times.
+package palette
+
+import "image/color"
+
+type Palette []color.RGBA
+
+// Builtin holds the compiled-in palettes by name.
+var Builtin = map[string]Palette{
+	"quake":  Quake,
+	"quake2": Quake2,
+}
+
+// At returns the color for index, wrapping around the palette.
+func (p Palette) At(index int) color.RGBA {
+	return p[(index)%len(p)]
+}
+
+// Interpolate blends c1 and c2 per sRGB channel, frac runs from 0 to 1. The
+// channels are rounded towards c1.
+func Interpolate(c1 color.RGBA, c2 color.RGBA, frac float64) color.RGBA {
+	f := func(a, b uint8) uint8 {
+		return uint8(int(a) + int((float64(b)-float64(a))*frac))
+	}
+	return color.RGBA{f(c1.R, c2.R), f(c1.G, c2.G), f(c1.B, c2.B), 255}
+}
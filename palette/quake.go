@@ -1,9 +1,9 @@
-package main
+package palette
 
 import "image/color"
 
 //https://quakewiki.org/wiki/Quake_palette
-var quake = []color.RGBA{
+var Quake = Palette{
 	color.RGBA{0, 0, 0, 255},
 	color.RGBA{15, 15, 15, 255},
 	color.RGBA{31, 31, 31, 255},
@@ -1,8 +1,8 @@
-package main
+package palette
 
 import "image/color"
 
-var quake2 = []color.RGBA{
+var Quake2 = Palette{
 	color.RGBA{0, 0, 0, 255},
 	color.RGBA{1, 1, 1, 255},
 	color.RGBA{1, 1, 1, 255},
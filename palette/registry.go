@@ -0,0 +1,78 @@
+package palette
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// Registry holds gradients by name.
+type Registry struct {
+	mu        sync.RWMutex
+	gradients map[string]*Gradient
+}
+
+func NewRegistry() *Registry {
+	return &Registry{gradients: map[string]*Gradient{}}
+}
+
+// Default is the registry of the command, it starts with the Builtin
+// palettes.
+var Default = NewRegistry()
+
+func init() {
+	for name, p := range Builtin {
+		Default.Register(name, p.Gradient())
+	}
+}
+
+// Register adds g under name, a gradient of the same name is replaced.
+// Registered gradients are shared, they must not be modified.
+func (r *Registry) Register(name string, g *Gradient) error {
+	if name == "" {
+		return errors.New("palette: empty name")
+	}
+	if len(g.Stops) == 0 {
+		return errors.New("palette: " + name + " has no colors")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gradients[name] = g
+	return nil
+}
+
+func (r *Registry) Lookup(name string) (*Gradient, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	g, ok := r.gradients[name]
+	return g, ok
+}
+
+// Names returns the registered names in alphabetical order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.gradients))
+	for name := range r.gradients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadFile registers all palettes of a palette file, see Load, and returns
+// their names.
+func (r *Registry) LoadFile(fileName string) ([]string, error) {
+	palettes, err := Load(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, p := range palettes {
+		if err := r.Register(p.Name, p.Gradient); err != nil {
+			return nil, err
+		}
+		names = append(names, p.Name)
+	}
+	return names, nil
+}
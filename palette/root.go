@@ -0,0 +1,44 @@
+package palette
+
+import (
+	"image/color"
+	"math"
+)
+
+// Root colors basins of attraction, one hue per root. The color gets darker
+// the more iterations a point needed to converge.
+func Root(root, roots int, iterations float64) color.RGBA {
+	hue := float64(root) / float64(roots)
+	value := 0.25 + 0.75*math.Exp(-iterations/12)
+	return HSV(hue, 0.8, value)
+}
+
+// HSV converts hue, saturation and value in [0, 1] to a color.
+// https://en.wikipedia.org/wiki/HSL_and_HSV#HSV_to_RGB
+func HSV(h, s, v float64) color.RGBA {
+	h = (h - math.Floor(h)) * 6
+	c := v * s
+	x := c * (1 - math.Abs(math.Mod(h, 2)-1))
+	var r, g, b float64
+	switch int(h) {
+	case 0:
+		r, g, b = c, x, 0
+	case 1:
+		r, g, b = x, c, 0
+	case 2:
+		r, g, b = 0, c, x
+	case 3:
+		r, g, b = 0, x, c
+	case 4:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	m := v - c
+	return color.RGBA{
+		uint8(math.Round((r + m) * 255)),
+		uint8(math.Round((g + m) * 255)),
+		uint8(math.Round((b + m) * 255)),
+		255,
+	}
+}
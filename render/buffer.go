@@ -0,0 +1,160 @@
+package render
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jfhaecker/mandelgo/fractal"
+)
+
+// Buffer holds the iteration data of an image, everything the coloring needs.
+// The pixels are stored row by row.
+type Buffer struct {
+	Width, Height int
+	MaxIter       int
+	Roots         int          // number of Newton roots, 0 for escape time fractals
+	Smooth        []float64    // smooth iteration count
+	Escaped       []bool       // false when the point reached MaxIter
+	Z             []complex128 // z after the last iteration
+	Derivative    []complex128 // dz/dc after the last iteration, 0 if not tracked
+	Distance      []float64    // distance estimate to the set in pixels, 0 if unknown
+	Root          []uint16     // Newton only, the 1-based root the point converged to
+	Period        []uint32     // length of the cycle found by Job.Periodicity, 0 if none
+	// Trap and TrapZ are the closest approach of the orbit to Job.Trap and
+	// where it happened, nil without trap data. Trap is +Inf for misses.
+	Trap  []float64
+	TrapZ []complex128
+}
+
+func NewBuffer(width, height, maxIter int) *Buffer {
+	n := width * height
+	return &Buffer{
+		Width:      width,
+		Height:     height,
+		MaxIter:    maxIter,
+		Smooth:     make([]float64, n),
+		Escaped:    make([]bool, n),
+		Z:          make([]complex128, n),
+		Derivative: make([]complex128, n),
+		Distance:   make([]float64, n),
+		Root:       make([]uint16, n),
+		Period:     make([]uint32, n),
+	}
+}
+
+// set stores point at index i, spacing is the distance between two pixels.
+func (b *Buffer) set(i int, point *fractal.Point, spacing float64) {
+	b.Smooth[i] = point.NormIterationCount
+	b.Escaped[i] = point.IterationCount != b.MaxIter
+	b.Z[i] = point.Zn
+	b.Derivative[i] = point.DZ
+	if point.Distance > 0 {
+		b.Distance[i] = point.Distance / spacing
+	}
+	b.Root[i] = uint16(point.Root)
+	b.Period[i] = uint32(point.Period)
+	if b.Trap != nil {
+		b.Trap[i], b.TrapZ[i] = point.Trap, point.TrapZ
+	}
+}
+
+// bufferMagic starts every buffer file, the last byte is the version.
+// Version 1 had no Distance, version 2 no trap data, version 3 no Period.
+var bufferMagic = [8]byte{'m', 'a', 'n', 'd', 'e', 'l', 'b', 4}
+
+type bufferHeader struct {
+	Magic                         [8]byte
+	Width, Height, MaxIter, Roots uint32
+}
+
+// WriteTo writes b in little endian: the header followed by the Smooth,
+// Escaped, Z, Derivative, Root and Distance arrays, a bool that tells if
+// the Trap and TrapZ arrays follow, those, and the Period array.
+func (b *Buffer) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	header := bufferHeader{bufferMagic, uint32(b.Width), uint32(b.Height), uint32(b.MaxIter), uint32(b.Roots)}
+	arrays := []any{header, b.Smooth, b.Escaped, b.Z, b.Derivative, b.Root, b.Distance, b.Trap != nil}
+	if b.Trap != nil {
+		arrays = append(arrays, b.Trap, b.TrapZ)
+	}
+	arrays = append(arrays, b.Period)
+	for _, data := range arrays {
+		if err := binary.Write(bw, binary.LittleEndian, data); err != nil {
+			return cw.n, err
+		}
+	}
+	err := bw.Flush()
+	return cw.n, err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// ReadBuffer reads a buffer written by WriteTo, also in the older versions.
+func ReadBuffer(r io.Reader) (*Buffer, error) {
+	br := bufio.NewReader(r)
+	var header bufferHeader
+	if err := binary.Read(br, binary.LittleEndian, &header); err != nil {
+		return nil, fmt.Errorf("buffer: %w", err)
+	}
+	version := header.Magic[7]
+	header.Magic[7] = bufferMagic[7]
+	if header.Magic != bufferMagic || version < 1 || version > bufferMagic[7] {
+		return nil, errors.New("buffer: not a mandelgo buffer")
+	}
+	if header.Width < 2 || header.Height < 2 || uint64(header.Width)*uint64(header.Height) > 1<<30 {
+		return nil, fmt.Errorf("buffer: bad size %vx%v", header.Width, header.Height)
+	}
+	b := NewBuffer(int(header.Width), int(header.Height), int(header.MaxIter))
+	b.Roots = int(header.Roots)
+	var trap bool
+	arrays := []any{b.Smooth, b.Escaped, b.Z, b.Derivative, b.Root, b.Distance, &trap}
+	switch version {
+	case 1:
+		arrays = arrays[:5]
+	case 2:
+		arrays = arrays[:6]
+	}
+	for _, data := range arrays {
+		if err := binary.Read(br, binary.LittleEndian, data); err != nil {
+			return nil, fmt.Errorf("buffer: %w", err)
+		}
+	}
+	arrays = nil
+	if trap {
+		b.Trap, b.TrapZ = make([]float64, len(b.Smooth)), make([]complex128, len(b.Smooth))
+		arrays = append(arrays, b.Trap, b.TrapZ)
+	}
+	if version >= 4 {
+		arrays = append(arrays, b.Period)
+	}
+	for _, data := range arrays {
+		if err := binary.Read(br, binary.LittleEndian, data); err != nil {
+			return nil, fmt.Errorf("buffer: %w", err)
+		}
+	}
+	return b, nil
+}
+
+// LoadBuffer reads a buffer file.
+func LoadBuffer(fileName string) (*Buffer, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadBuffer(f)
+}
@@ -0,0 +1,60 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/jfhaecker/mandelgo/fractal"
+	"github.com/jfhaecker/mandelgo/viewport"
+)
+
+func TestBufferRoundTrip(t *testing.T) {
+	for _, trap := range []fractal.Trap{nil, fractal.CircleTrap{Radius: 1}} {
+		job := benchJob(viewport.Locations[6])
+		job.Width, job.Height = 64, 48
+		job.Distance, job.Trap, job.Periodicity = true, trap, 1e-9
+		buf, _, err := Compute(context.Background(), job)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (buf.Trap != nil) != (trap != nil) {
+			t.Fatalf("trap %v: trap data %v", trap, buf.Trap != nil)
+		}
+		periodic := false
+		for _, p := range buf.Period {
+			periodic = periodic || p > 0
+		}
+		if !periodic {
+			t.Errorf("trap %v: no periods", trap)
+		}
+		var b bytes.Buffer
+		if _, err := buf.WriteTo(&b); err != nil {
+			t.Fatal(err)
+		}
+		got, err := ReadBuffer(&b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, buf) {
+			t.Errorf("trap %v: buffer changed on the way through a file", trap)
+		}
+
+		// version 3 ends before the periods
+		b.Reset()
+		buf.WriteTo(&b)
+		old := b.Bytes()[:b.Len()-4*len(buf.Period)]
+		old[7] = 3
+		if got, err = ReadBuffer(bytes.NewReader(old)); err != nil {
+			t.Fatal(err)
+		}
+		buf.Period = make([]uint32, len(buf.Period))
+		if !reflect.DeepEqual(got, buf) {
+			t.Errorf("trap %v: version 3 buffer read wrong", trap)
+		}
+	}
+	if _, err := ReadBuffer(bytes.NewReader([]byte("not a buffer at all"))); err == nil {
+		t.Error("garbage read as a buffer")
+	}
+}
@@ -0,0 +1,120 @@
+package render
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"math"
+	"runtime"
+
+	"github.com/jfhaecker/mandelgo/fractal"
+	"github.com/jfhaecker/mandelgo/palette"
+)
+
+// Coloring turns a Buffer into an image.
+type Coloring struct {
+	Gradient *palette.Gradient
+	// Histogram maps the smooth iteration counts through their distribution
+	// before they are looked up in Gradient, nil uses them as they are.
+	Histogram *Histogram
+
+	// The distance estimate of Job.Distance gives pixels near the boundary
+	// of the set their own look, pixels without an estimate keep their color.
+	//
+	// Boundary draws lines of about Boundary pixels width in BoundaryColor
+	// where the set is, even filaments thinner than a pixel. The alpha of
+	// BoundaryColor is ignored.
+	Boundary      float64
+	BoundaryColor color.RGBA
+	// Shading from 0 to 1 darkens the colors towards the boundary.
+	Shading float64
+
+	// Trap colors the pixels of buffers with trap data by the closest
+	// approach of their orbit to it, inside of the set too: Gradient at the
+	// distance in units of TrapScale, 0 means 1. A fractal.ImageTrap paints
+	// the color of the image where the orbit hit it over the iteration color
+	// instead. Pixels whose orbit missed the trap keep the iteration color,
+	// black inside of the set.
+	Trap      fractal.Trap
+	TrapScale float64
+}
+
+// shadingFalloff is the distance in pixels from which Shading leaves the
+// colors alone.
+const shadingFalloff = 64
+
+// Paint colors buf row by row on workers goroutines, 0 means GOMAXPROCS.
+func (c Coloring) Paint(buf *Buffer, workers int) *image.RGBA {
+	if workers == 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if c.Histogram != nil {
+		c.Histogram.Add(buf)
+	}
+	img := image.NewRGBA(image.Rect(0, 0, buf.Width, buf.Height))
+	parallel(context.Background(), workers, buf.Height, func(y int) {
+		for x := 0; x < buf.Width; x++ {
+			img.SetRGBA(x, y, c.color(buf, y*buf.Width+x))
+		}
+	})
+	return img
+}
+
+// https://en.wikipedia.org/wiki/Mandelbrot_set#Continuous_.28smooth.29_coloring
+func (c Coloring) color(buf *Buffer, i int) color.RGBA {
+	trapped := c.Trap != nil && buf.Trap != nil && !math.IsInf(buf.Trap[i], 1)
+	if !buf.Escaped[i] && !trapped {
+		return color.RGBA{0, 0, 0, 255}
+	}
+	if buf.Roots > 0 {
+		return palette.Root(int(buf.Root[i])-1, buf.Roots, buf.Smooth[i])
+	}
+	var co color.RGBA
+	imgTrap, isImage := c.Trap.(*fractal.ImageTrap)
+	switch {
+	case trapped && !isImage:
+		scale := c.TrapScale
+		if scale == 0 {
+			scale = 1
+		}
+		co = c.Gradient.At(buf.Trap[i] / scale)
+	case !buf.Escaped[i]:
+		co = color.RGBA{0, 0, 0, 255}
+	case c.Histogram != nil:
+		co = c.Gradient.At(c.Histogram.at(buf.Smooth[i]))
+	default:
+		co = c.Gradient.Iteration(buf.Smooth[i])
+	}
+	if trapped && isImage {
+		t := imgTrap.At(buf.TrapZ[i])
+		co = mix(co, color.RGBA{t.R, t.G, t.B, 255}, float64(t.A)/255)
+	}
+	if d := buf.Distance[i]; d > 0 {
+		co = c.distance(co, d)
+	}
+	return co
+}
+
+// distance applies Shading and Boundary to co for a pixel d pixels away
+// from the set.
+// https://mrob.com/pub/muency/distanceestimator.html
+func (c Coloring) distance(co color.RGBA, d float64) color.RGBA {
+	if c.Shading > 0 {
+		v := math.Min(1, math.Log2(1+d)/math.Log2(1+shadingFalloff))
+		co = mix(color.RGBA{0, 0, 0, 255}, co, 1-c.Shading*(1-v))
+	}
+	if c.Boundary > 0 {
+		// the pixel center is d away, the line covers it up to half a pixel beyond
+		if a := math.Min(1, c.Boundary-d+0.5); a > 0 {
+			co = mix(co, c.BoundaryColor, a)
+		}
+	}
+	return co
+}
+
+func mix(c1, c2 color.RGBA, t float64) color.RGBA {
+	f := func(a, b uint8) uint8 {
+		return uint8(math.Round(float64(a) + (float64(b)-float64(a))*t))
+	}
+	return color.RGBA{f(c1.R, c2.R), f(c1.G, c2.G), f(c1.B, c2.B), 255}
+}
@@ -0,0 +1,149 @@
+package render
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/cmplx"
+	"strings"
+)
+
+// exportFields are the per-pixel fields of the exports: the smooth iteration
+// count, whether the point escaped and |z| after the last iteration, which is
+// 0 for points that were never iterated like those in the main cardioid.
+var exportFields = []ExportField{
+	{"smooth", "<f8"},
+	{"escaped", "|b1"},
+	{"abs_z", "<f8"},
+}
+
+// ExportField is the name and NumPy dtype of an exported field.
+type ExportField struct {
+	Name  string `json:"name"`
+	DType string `json:"dtype"`
+}
+
+// WriteNPY writes the smooth iteration counts, escape flags and final |z| of
+// buf as a NumPy structured array of shape (Height, Width):
+//
+//	a = numpy.load("mandel-000.npy")
+//	a["smooth"][y, x]
+//
+// https://numpy.org/doc/stable/reference/generated/numpy.lib.format.html
+func WriteNPY(w io.Writer, buf *Buffer) error {
+	var descr []string
+	for _, f := range exportFields {
+		descr = append(descr, fmt.Sprintf("('%v', '%v')", f.Name, f.DType))
+	}
+	header := fmt.Sprintf("{'descr': [%v], 'fortran_order': False, 'shape': (%v, %v), }",
+		strings.Join(descr, ", "), buf.Height, buf.Width)
+	// magic, version and header length take 10 bytes, the header ends with a
+	// newline and is padded so the data starts at a multiple of 64
+	pad := 64 - (10+len(header)+1)%64
+	header += strings.Repeat(" ", pad%64) + "\n"
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString("\x93NUMPY\x01\x00")
+	binary.Write(bw, binary.LittleEndian, uint16(len(header)))
+	bw.WriteString(header)
+	var record [17]byte
+	for i := range buf.Smooth {
+		binary.LittleEndian.PutUint64(record[0:], math.Float64bits(buf.Smooth[i]))
+		record[8] = 0
+		if buf.Escaped[i] {
+			record[8] = 1
+		}
+		binary.LittleEndian.PutUint64(record[9:], math.Float64bits(cmplx.Abs(buf.Z[i])))
+		bw.Write(record[:])
+	}
+	return bw.Flush()
+}
+
+// Sidecar describes a raw export, it is saved as JSON next to it.
+type Sidecar struct {
+	Format        string        `json:"format"`
+	Version       int           `json:"version"`
+	Compression   string        `json:"compression"`
+	ByteOrder     string        `json:"byte_order"`
+	Width         int           `json:"width"`
+	Height        int           `json:"height"`
+	Fields        []ExportField `json:"fields"`
+	Rectangle     RawRectangle  `json:"rectangle"`
+	MaxIter       int           `json:"max_iter"`
+	BailoutRadius float64       `json:"bailout_radius"`
+	JuliaC        []float64     `json:"julia_c,omitempty"` // [re, im] of Julia sets
+}
+
+// RawRectangle is the viewport.Rectangle of a raw export. Deep zooms also
+// get their exact center and width, float64 cannot hold them.
+type RawRectangle struct {
+	TopLeft     [2]float64 `json:"top_left"`
+	BottomRight [2]float64 `json:"bottom_right"`
+	Center      [2]float64 `json:"center"`
+	Width       float64    `json:"width"`
+	Height      float64    `json:"height"`
+	ExactCenter []string   `json:"exact_center,omitempty"`
+	ExactWidth  string     `json:"exact_width,omitempty"`
+	ExactHeight string     `json:"exact_height,omitempty"`
+}
+
+// NewSidecar describes the raw export of buf computed for job.
+func NewSidecar(job *Job, buf *Buffer) Sidecar {
+	view := job.View
+	if job.BigView != nil {
+		view = job.BigView.Rectangle()
+	}
+	pair := func(c complex128) [2]float64 { return [2]float64{real(c), imag(c)} }
+	rect := RawRectangle{
+		TopLeft:     pair(view.TopLeft),
+		BottomRight: pair(view.BottomRight),
+		Center:      pair(view.Center),
+		Width:       view.Width,
+		Height:      view.Height,
+	}
+	if job.BigView != nil {
+		rect.ExactCenter = []string{job.BigView.Center.X.Text('g', -1), job.BigView.Center.Y.Text('g', -1)}
+		rect.ExactWidth = job.BigView.Width.String()
+		rect.ExactHeight = job.BigView.Height.String()
+	}
+	sidecar := Sidecar{
+		Format:        "mandelgo-raw",
+		Version:       1,
+		Compression:   "gzip",
+		ByteOrder:     "little",
+		Width:         buf.Width,
+		Height:        buf.Height,
+		Fields:        exportFields,
+		Rectangle:     rect,
+		MaxIter:       buf.MaxIter,
+		BailoutRadius: job.BailoutRadius,
+	}
+	if job.Julia {
+		sidecar.JuliaC = []float64{real(job.C), imag(job.C)}
+	}
+	return sidecar
+}
+
+// WriteRaw writes the fields of buf as gzip compressed little endian arrays,
+// one after the other in the order of Sidecar.Fields, each with one value per
+// pixel row by row. Booleans take one byte.
+func WriteRaw(w io.Writer, buf *Buffer) error {
+	zw := gzip.NewWriter(w)
+	bw := bufio.NewWriter(zw)
+	absZ := make([]float64, len(buf.Z))
+	for i, z := range buf.Z {
+		absZ[i] = cmplx.Abs(z)
+	}
+	for _, data := range []any{buf.Smooth, buf.Escaped, absZ} {
+		if err := binary.Write(bw, binary.LittleEndian, data); err != nil {
+			return err
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
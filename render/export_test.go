@@ -0,0 +1,79 @@
+package render
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"math"
+	"strings"
+	"testing"
+)
+
+func testBuffer() *Buffer {
+	buf := NewBuffer(5, 3, 100)
+	for i := range buf.Smooth {
+		buf.Smooth[i] = float64(i) + 0.5
+		buf.Escaped[i] = i%2 == 0
+		buf.Z[i] = complex(3, 4)
+	}
+	return buf
+}
+
+func TestWriteNPY(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteNPY(&b, testBuffer()); err != nil {
+		t.Fatal(err)
+	}
+	data := b.Bytes()
+	if !bytes.HasPrefix(data, []byte("\x93NUMPY\x01\x00")) {
+		t.Fatalf("bad magic %q", data[:8])
+	}
+	n := int(binary.LittleEndian.Uint16(data[8:]))
+	header := string(data[10 : 10+n])
+	if (10+n)%64 != 0 || !strings.HasSuffix(header, "\n") || !strings.Contains(header, "'shape': (3, 5)") {
+		t.Errorf("bad header %q", header)
+	}
+	records := data[10+n:]
+	if len(records) != 15*17 {
+		t.Fatalf("%v bytes of data, want %v", len(records), 15*17)
+	}
+	last := records[14*17:]
+	if math.Float64frombits(binary.LittleEndian.Uint64(last)) != 14.5 || last[8] != 1 ||
+		math.Float64frombits(binary.LittleEndian.Uint64(last[9:])) != 5 {
+		t.Errorf("bad last record %v", last)
+	}
+}
+
+func TestWriteRaw(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteRaw(&b, testBuffer()); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := gzip.NewReader(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 15*17 {
+		t.Fatalf("%v bytes, want %v", len(data), 15*17)
+	}
+	if math.Float64frombits(binary.LittleEndian.Uint64(data[8:])) != 1.5 || data[15*8+1] != 0 ||
+		math.Float64frombits(binary.LittleEndian.Uint64(data[15*9:])) != 5 {
+		t.Error("fields not in sidecar order")
+	}
+}
+
+func TestSidecarJulia(t *testing.T) {
+	job := &Job{BailoutRadius: 2}
+	if s := NewSidecar(job, testBuffer()); s.JuliaC != nil {
+		t.Errorf("Mandelbrot sidecar with julia_c %v", s.JuliaC)
+	}
+	job.Julia, job.C = true, -0.4+0.6i
+	if s := NewSidecar(job, testBuffer()); len(s.JuliaC) != 2 || s.JuliaC[0] != -0.4 || s.JuliaC[1] != 0.6 {
+		t.Errorf("julia_c %v, want [-0.4 0.6]", s.JuliaC)
+	}
+}
@@ -0,0 +1,74 @@
+package render
+
+import "math"
+
+// Histogram spreads the colors by the distribution of the smooth iteration
+// counts instead of their value, a frame whose pixels all fall into a narrow
+// band still gets the whole gradient. It keeps the distribution of earlier
+// frames, so it must not be shared by Paint calls running at the same time.
+// https://en.wikipedia.org/wiki/Plotting_algorithms_for_the_Mandelbrot_set#Histogram_coloring
+type Histogram struct {
+	// Smoothing in [0, 1) is the weight of the earlier frames, a zoom does
+	// not flicker when the distribution jumps between frames. 0 uses every
+	// frame on its own.
+	Smoothing float64
+
+	// share[i] is the fraction of the escaped pixels with i iterations
+	share []float64
+	// below[i] is the fraction with less than i iterations
+	below []float64
+}
+
+// Add blends the distribution of buf into h, Paint does it for every frame
+// it colors. Frames that are not painted again, like those skipped by a
+// resumed zoom, are added this way. Newton buffers are left out.
+func (h *Histogram) Add(buf *Buffer) {
+	if buf.Roots > 0 {
+		return
+	}
+	counts := make([]float64, buf.MaxIter+1)
+	total := 0
+	for i, n := range buf.Smooth {
+		if buf.Escaped[i] {
+			counts[h.bin(n, len(counts))]++
+			total++
+		}
+	}
+	if len(h.share) < len(counts) {
+		h.share = append(h.share, make([]float64, len(counts)-len(h.share))...)
+	}
+	first := h.below == nil
+	for i := range h.share {
+		c := 0.0
+		if i < len(counts) && total > 0 {
+			c = counts[i] / float64(total)
+		}
+		if first {
+			h.share[i] = c
+		} else {
+			h.share[i] = h.Smoothing*h.share[i] + (1-h.Smoothing)*c
+		}
+	}
+
+	h.below = make([]float64, len(h.share)+1)
+	for i, s := range h.share {
+		h.below[i+1] = h.below[i] + s
+	}
+	if sum := h.below[len(h.share)]; sum > 0 {
+		for i := range h.below {
+			h.below[i] /= sum
+		}
+	}
+}
+
+func (h *Histogram) bin(n float64, bins int) int {
+	return min(max(int(math.Floor(n)), 0), bins-1)
+}
+
+// at returns the position in [0, 1] of the smooth iteration count n, the
+// fraction of pixels below it.
+func (h *Histogram) at(n float64) float64 {
+	i := h.bin(n, len(h.share))
+	frac := math.Min(math.Max(n-float64(i), 0), 1)
+	return h.below[i] + frac*(h.below[i+1]-h.below[i])
+}
@@ -0,0 +1,56 @@
+package render
+
+import (
+	"math"
+	"testing"
+)
+
+// bandBuffer has escaped pixels with smooth iteration counts evenly spread
+// from lo to hi and one pixel inside.
+func bandBuffer(lo, hi float64) *Buffer {
+	buf := NewBuffer(100, 2, 1000)
+	for i := range buf.Smooth {
+		buf.Smooth[i] = lo + (hi-lo)*float64(i)/float64(len(buf.Smooth)-1)
+		buf.Escaped[i] = true
+	}
+	buf.Escaped[0] = false
+	return buf
+}
+
+func TestHistogramSpreadsBand(t *testing.T) {
+	h := &Histogram{}
+	h.Add(bandBuffer(500, 504))
+	for _, tc := range []struct{ n, want float64 }{
+		{0, 0},
+		{500, 0},
+		{502, 0.5},
+		{504, 1},
+		{1000, 1},
+	} {
+		if got := h.at(tc.n); math.Abs(got-tc.want) > 0.02 {
+			t.Errorf("at(%v) = %v, want %v", tc.n, got, tc.want)
+		}
+	}
+	for n, last := 500.0, -1.0; n < 504; n += 0.01 {
+		got := h.at(n)
+		if got < last {
+			t.Fatalf("at(%v) = %v is below %v", n, got, last)
+		}
+		last = got
+	}
+}
+
+func TestHistogramSmoothing(t *testing.T) {
+	plain, smoothed := &Histogram{}, &Histogram{Smoothing: 0.5}
+	for _, h := range []*Histogram{plain, smoothed} {
+		h.Add(bandBuffer(100, 200))
+		h.Add(bandBuffer(150, 250))
+	}
+	if got := plain.at(150); got > 0.01 {
+		t.Errorf("without smoothing at(150) = %v, want 0", got)
+	}
+	// half of the first frame lies below 150
+	if got := smoothed.at(150); math.Abs(got-0.25) > 0.02 {
+		t.Errorf("with smoothing at(150) = %v, want 0.25", got)
+	}
+}
@@ -0,0 +1,145 @@
+package render
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"sync"
+	"sync/atomic"
+
+	"github.com/jfhaecker/mandelgo/floatexp"
+	"github.com/jfhaecker/mandelgo/fractal"
+)
+
+// maxReferences limits the reference orbits per image. Points that are still
+// glitched after that are computed with math/big.
+var maxReferences = 16
+
+// seriesError is the truncation error allowed to the series approximation
+// in pixels, see fractal.Orbit.Series. Close to the boundary the smooth
+// iteration count changes by whole iterations within a thousandth of a
+// pixel, the iterations it costs to go lower are few.
+const seriesError = 1e-6
+
+// renderPerturbation computes a deep zoom with one arbitrary precision
+// reference orbit at the center and float64 deltas for every pixel. Glitched
+// pixels are iterated again against a reference picked among them. Beyond
+// the range of float64 the deltas start out in floatexp.
+func renderPerturbation(ctx context.Context, job *Job, prec uint, workers int, points []fractal.Point, stats *Stats) {
+	view := job.BigView
+	offsets := make([]floatexp.Complex, len(points))
+	center := view.Center.Complex()
+	pending := make([]int, len(points))
+	for i := range points {
+		x, y := i%job.Width, i/job.Width
+		offsets[i] = view.Offset(x, y, job.Width, job.Height)
+		points[i] = fractal.Point{Z: center + offsets[i].Complex128(), X: x, Y: y}
+		pending[i] = i
+	}
+	deep := view.Spacing(job.Width, job.Height).Log2() < fractal.MinExp
+
+	cx := new(big.Float).SetPrec(prec).Set(view.Center.X)
+	cy := new(big.Float).SetPrec(prec).Set(view.Center.Y)
+	ref := fractal.NewOrbit(cx, cy, job.MaxIter, job.BailoutRadius)
+	ref.Derivative = job.Distance && !deep
+	ref.Trap = job.Trap
+	refOffset := floatexp.Complex{}
+	// the top left corner is the farthest from the center, the trap has to
+	// see every iteration
+	var series fractal.Series
+	if job.Trap == nil {
+		maxError := view.Spacing(job.Width, job.Height).Mul(floatexp.New(seriesError))
+		series = ref.Series(offsets[0].Abs(), maxError, job.MaxIter-1)
+	}
+	stats.Perturbation = true
+	stats.Skipped = series.Skip
+
+	glitched := make([]bool, len(points))
+	closeness := make([]float64, len(points))
+	for round := 0; round < maxReferences && len(pending) > 0; round++ {
+		stats.References++
+		parallel(ctx, workers, len(pending), func(k int) {
+			i := pending[k]
+			points[i].IterationCount = 0
+			dc := offsets[i].Sub(refOffset)
+			skip := 0
+			if round == 0 {
+				skip = series.Skip
+			}
+			glitched[i], closeness[i] = iterate(job, ref, &points[i], series, skip, dc, deep)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		next := pending[:0]
+		best, bestCloseness := -1, math.Inf(1)
+		for _, i := range pending {
+			if glitched[i] {
+				next = append(next, i)
+				if best < 0 || closeness[i] < bestCloseness {
+					best, bestCloseness = i, closeness[i]
+				}
+			}
+		}
+		pending = next
+		if best >= 0 {
+			c := view.At(points[best].X, points[best].Y, job.Width, job.Height, prec)
+			ref = fractal.NewOrbit(c.X, c.Y, job.MaxIter, job.BailoutRadius)
+			ref.Derivative = job.Distance && !deep
+			ref.Trap = job.Trap
+			refOffset = offsets[best]
+		}
+	}
+
+	stats.Unresolved = len(pending)
+	parallel(ctx, workers, len(pending), func(k int) {
+		p := &points[pending[k]]
+		p.DZ = 0
+		c := view.At(p.X, p.Y, job.Width, job.Height, prec)
+		fractal.BigMandelbrot(p, c.X, c.Y, job.MaxIter, job.BailoutRadius, job.Trap)
+	})
+}
+
+// iterate computes a point against ref, skipping the first skip iterations
+// with series.
+func iterate(job *Job, ref *fractal.Orbit, point *fractal.Point, series fractal.Series, skip int, dc floatexp.Complex, deep bool) (bool, float64) {
+	d := dc
+	point.Trap = math.Inf(1)
+	if ref.Derivative {
+		point.DZ = 1
+	}
+	if skip > 0 {
+		d = series.Delta(dc)
+		if ref.Derivative {
+			point.DZ = series.Derivative(dc).Complex128()
+		}
+	}
+	if deep {
+		return ref.IterateExp(point, skip, d, dc, job.MaxIter, job.BailoutRadius)
+	}
+	return ref.IterateFrom(point, skip, d.Complex128(), dc.Complex128(), job.MaxIter, job.BailoutRadius)
+}
+
+// parallel calls fn for 0 <= i < n, spread over workers in chunks.
+func parallel(ctx context.Context, workers, n int, fn func(i int)) {
+	const chunk = 256
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				start := int(next.Add(chunk)) - chunk
+				if start >= n {
+					return
+				}
+				for i := start; i < min(start+chunk, n); i++ {
+					fn(i)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
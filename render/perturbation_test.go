@@ -0,0 +1,84 @@
+package render
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/jfhaecker/mandelgo/fractal"
+	"github.com/jfhaecker/mandelgo/palette"
+	"github.com/jfhaecker/mandelgo/viewport"
+)
+
+// deepJob is a 1e-20 wide view at center, too deep for float64. The middle
+// row lies on the real axis.
+func deepJob(t *testing.T, center string) Job {
+	c, err := viewport.ParseBigPoint(center)
+	if err != nil {
+		t.Fatal(err)
+	}
+	view := viewOfWidth("1e-20")
+	view.Center = c
+	job := Job{Width: 24, Height: 17, MaxIter: 3000, BailoutRadius: 20, Palette: palette.Quake, BigView: view}
+	if job.precision() == 0 {
+		t.Fatal("the view is not deep enough for perturbation")
+	}
+	return job
+}
+
+// checkDirect compares job rendered with perturbation against iterating
+// every pixel with math/big.
+func checkDirect(t *testing.T, job Job) Stats {
+	buf, stats, err := Compute(context.Background(), job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stats.Perturbation {
+		t.Fatal("rendered without perturbation")
+	}
+	prec := job.precision()
+	escaped := 0
+	for i := range buf.Smooth {
+		c := job.BigView.At(i%job.Width, i/job.Width, job.Width, job.Height, prec)
+		var want fractal.Point
+		fractal.BigMandelbrot(&want, c.X, c.Y, job.MaxIter, job.BailoutRadius, nil)
+		if want.IterationCount == job.MaxIter {
+			if buf.Escaped[i] {
+				t.Errorf("pixel %v escaped, want inside", i)
+			}
+			continue
+		}
+		escaped++
+		if !buf.Escaped[i] || math.Abs(buf.Smooth[i]-want.NormIterationCount) > 1e-3 {
+			t.Errorf("pixel %v: smooth %v (escaped %v), want %v", i, buf.Smooth[i], buf.Escaped[i], want.NormIterationCount)
+		}
+	}
+	if escaped == 0 {
+		t.Error("no pixel escaped")
+	}
+	return stats
+}
+
+func TestPerturbation(t *testing.T) {
+	stats := checkDirect(t, deepJob(t, "1i"))
+	if stats.Skipped == 0 || stats.References != 1 {
+		t.Errorf("skipped %v iterations with %v references, want some with one", stats.Skipped, stats.References)
+	}
+}
+
+// Around -2 the pixels off the real axis escape, the ones on it right
+// of it stay in [-2, 2] and pass close to 0 where their deltas glitch.
+func TestPerturbationGlitches(t *testing.T) {
+	job := deepJob(t, "-2")
+	stats := checkDirect(t, job)
+	if stats.References < 2 {
+		t.Errorf("%v references, want glitches", stats.References)
+	}
+
+	defer func(n int) { maxReferences = n }(maxReferences)
+	maxReferences = 1
+	stats = checkDirect(t, job)
+	if stats.Unresolved == 0 {
+		t.Error("no pixel was left to math/big")
+	}
+}
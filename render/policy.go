@@ -0,0 +1,71 @@
+package render
+
+import (
+	"math"
+
+	"github.com/jfhaecker/mandelgo/viewport"
+)
+
+// IterationPolicy picks MaxIter for every frame of a zoom.
+type IterationPolicy interface {
+	// MaxIter returns the iterations for view, prev describes the previous
+	// frame and is nil for the first one.
+	MaxIter(view *viewport.BigRectangle, prev *Stats) int
+}
+
+// Fixed uses the same number of iterations for every frame.
+type Fixed int
+
+func (f Fixed) MaxIter(view *viewport.BigRectangle, prev *Stats) int {
+	return int(f)
+}
+
+// fullWidth is the width of a view of the whole Mandelbrot set.
+const fullWidth = 4
+
+// Depth grows the iterations with the zoom depth:
+//
+//	Base * log10(zoom)^Exponent
+//
+// where zoom is the magnification against a view of the whole set. It never
+// returns less than Min or more than Max, Max 0 means no limit.
+// https://math.stackexchange.com/questions/16970/a-way-to-determine-the-ideal-number-of-maximum-iterations-for-an-arbitrary-zoom
+type Depth struct {
+	Min, Max       int
+	Base, Exponent float64
+}
+
+func (d Depth) MaxIter(view *viewport.BigRectangle, prev *Stats) int {
+	zoom := math.Log2(fullWidth) - view.Width.Log2()
+	n := d.Base * math.Pow(math.Max(0, zoom*math.Log10(2)), d.Exponent)
+	return clampIter(n, d.Min, d.Max)
+}
+
+// Adaptive starts with Min iterations and multiplies them by Growth whenever
+// more than Target of the pixels of the previous frame reached MaxIter, those
+// are often just under-iterated. It never goes beyond Max, Max 0 means no
+// limit. The iterations never shrink, a zoom only gets deeper.
+type Adaptive struct {
+	Min, Max int
+	Target   float64 // fraction of pixels
+	Growth   float64
+}
+
+func (a Adaptive) MaxIter(view *viewport.BigRectangle, prev *Stats) int {
+	if prev == nil || prev.MaxIter == 0 {
+		return clampIter(float64(a.Min), a.Min, a.Max)
+	}
+	n := float64(prev.MaxIter)
+	if prev.InsideFraction() > a.Target {
+		n *= a.Growth
+	}
+	return clampIter(n, a.Min, a.Max)
+}
+
+func clampIter(n float64, lo, hi int) int {
+	n = math.Max(n, float64(lo))
+	if hi > 0 {
+		n = math.Min(n, float64(hi))
+	}
+	return max(1, int(n))
+}
@@ -0,0 +1,61 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/jfhaecker/mandelgo/floatexp"
+	"github.com/jfhaecker/mandelgo/viewport"
+)
+
+func viewOfWidth(width string) *viewport.BigRectangle {
+	w, err := floatexp.ParseFloat(width)
+	if err != nil {
+		panic(err)
+	}
+	return &viewport.BigRectangle{Width: w, Height: w}
+}
+
+func TestFixed(t *testing.T) {
+	if n := Fixed(700).MaxIter(viewOfWidth("1e-9"), &Stats{MaxIter: 5000, Pixels: 1, Inside: 1}); n != 700 {
+		t.Errorf("got %v, want 700", n)
+	}
+}
+
+func TestDepth(t *testing.T) {
+	d := Depth{Min: 100, Max: 20000, Base: 50, Exponent: 1}
+	for _, tc := range []struct {
+		width string
+		want  int
+	}{
+		{"4", 100},         // whole set, Min
+		{"4e-6", 300},      // zoom 1e6
+		{"4e-100", 5000},   // zoom 1e100
+		{"4e-1000", 20000}, // Max
+	} {
+		if n := d.MaxIter(viewOfWidth(tc.width), nil); n < tc.want-1 || n > tc.want {
+			t.Errorf("width %v: got %v, want %v", tc.width, n, tc.want)
+		}
+	}
+	if n := (Depth{Min: 100, Base: 50, Exponent: 1}).MaxIter(viewOfWidth("4e-1000"), nil); n < 49999 {
+		t.Errorf("without Max got %v, want 50000", n)
+	}
+}
+
+func TestAdaptive(t *testing.T) {
+	a := Adaptive{Min: 1000, Max: 1500, Target: 0.1, Growth: 1.25}
+	view := viewOfWidth("1")
+	for _, tc := range []struct {
+		prev *Stats
+		want int
+	}{
+		{nil, 1000},
+		{&Stats{MaxIter: 1000, Pixels: 100, Inside: 10}, 1000}, // at Target
+		{&Stats{MaxIter: 1000, Pixels: 100, Inside: 11}, 1250},
+		{&Stats{MaxIter: 1250, Pixels: 100, Inside: 0}, 1250},  // never shrinks
+		{&Stats{MaxIter: 1250, Pixels: 100, Inside: 50}, 1500}, // Max
+	} {
+		if n := a.MaxIter(view, tc.prev); n != tc.want {
+			t.Errorf("prev %+v: got %v, want %v", tc.prev, n, tc.want)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+// Package render runs the worker pipeline that turns a Job into an image.
+package render
+
+import (
+	"context"
+	"errors"
+	"image"
+	"runtime"
+
+	"github.com/jfhaecker/mandelgo/fractal"
+	"github.com/jfhaecker/mandelgo/palette"
+	"github.com/jfhaecker/mandelgo/viewport"
+)
+
+// Job describes a single image.
+type Job struct {
+	View          viewport.Rectangle
+	Width, Height int
+	MaxIter       int
+	BailoutRadius float64
+	Workers       int // defaults to GOMAXPROCS
+	Palette       palette.Palette
+	Gradient      *palette.Gradient // overrides Palette
+	Formula       fractal.Formula   // defaults to fractal.Quadratic
+	Julia         bool              // render the Julia set of C instead of the Mandelbrot set
+	C             complex128        // Julia constant
+	Kernel        fractal.Kernel    // overrides Formula, Julia and C
+	Periodicity   float64           // cycle detection tolerance in pixels, 0 disables it
+	Strategy      Strategy
+	// Distance tracks dz/dc to estimate the distance of every escaped pixel
+	// to the set, for the Quadratic formula down to zooms of about 1e-290.
+	Distance bool
+	// Trap records the closest approach of every orbit to it for orbit trap
+	// coloring, see Coloring.Trap. Custom Kernels have no trap data.
+	Trap fractal.Trap
+	// BigView overrides View. Deep Mandelbrot zooms are then computed with
+	// perturbation once float64 cannot resolve the pixels.
+	BigView *viewport.BigRectangle
+}
+
+// precision returns the mantissa bits needed for the pixels of job, 0 when
+// the float64 kernel is good enough or there is no arbitrary precision kernel.
+func (job *Job) precision() uint {
+	if job.BigView == nil || job.Kernel != nil || job.Julia {
+		return 0
+	}
+	if _, ok := job.Formula.(fractal.Quadratic); job.Formula != nil && !ok {
+		return 0
+	}
+	return job.BigView.Precision(job.Width, job.Height)
+}
+
+// connected tells if the set of job is known to be connected, which is only
+// the case for the quadratic Mandelbrot set.
+func (job *Job) connected() bool {
+	if job.Kernel != nil || job.Julia {
+		return false
+	}
+	_, quadratic := job.Formula.(fractal.Quadratic)
+	return job.Formula == nil || quadratic
+}
+
+func (job *Job) gradient() *palette.Gradient {
+	if job.Gradient != nil {
+		return job.Gradient
+	}
+	return job.Palette.Gradient()
+}
+
+func (job *Job) kernel() fractal.Kernel {
+	if job.Kernel != nil {
+		return job.Kernel
+	}
+	formula := job.Formula
+	if formula == nil {
+		formula = fractal.Quadratic{}
+	}
+	return fractal.Escape{
+		Formula:       formula,
+		BailoutRadius: job.BailoutRadius,
+		Julia:         job.Julia,
+		C:             job.C,
+		Periodicity:   job.Periodicity * job.View.Spacing(job.Width, job.Height),
+		Derivative:    job.Distance,
+		Trap:          job.Trap,
+	}
+}
+
+func (job *Job) validate() error {
+	switch {
+	case job.Width < 2 || job.Height < 2:
+		return errors.New("render: image must be at least 2x2 pixels")
+	case job.MaxIter < 1:
+		return errors.New("render: MaxIter must be positive")
+	case job.BailoutRadius < 2:
+		return errors.New("render: BailoutRadius must be at least 2")
+	case job.Workers < 0:
+		return errors.New("render: Workers must not be negative")
+	case len(job.Palette) == 0 && job.Gradient == nil:
+		return errors.New("render: empty palette")
+	case job.Gradient != nil && len(job.Gradient.Stops) == 0:
+		return errors.New("render: gradient without stops")
+	case job.Strategy < Tiles || job.Strategy > SubdivideInterior:
+		return errors.New("render: unknown Strategy")
+	}
+	return nil
+}
+
+// Stats describes how an image was computed.
+type Stats struct {
+	MaxIter      int
+	Pixels       int
+	Inside       int // pixels that reached MaxIter
+	Perturbation bool
+	References   int // reference orbits
+	Skipped      int // iterations per pixel skipped by series approximation
+	Unresolved   int // glitched pixels computed with math/big
+}
+
+// InsideFraction returns the fraction of pixels that reached MaxIter.
+func (s *Stats) InsideFraction() float64 {
+	if s.Pixels == 0 {
+		return 0
+	}
+	return float64(s.Inside) / float64(s.Pixels)
+}
+
+// Render computes the image described by job. Square tiles are spread over
+// job.Workers workers that write into a shared buffer, the image is painted
+// from it when all points are done.
+func Render(ctx context.Context, job Job) (*image.RGBA, error) {
+	img, _, err := RenderStats(ctx, job)
+	return img, err
+}
+
+// RenderStats is Render that also tells how the image was computed.
+func RenderStats(ctx context.Context, job Job) (*image.RGBA, Stats, error) {
+	buf, stats, err := Compute(ctx, job)
+	if err != nil {
+		return nil, stats, err
+	}
+	return Coloring{Gradient: job.gradient(), Trap: job.Trap}.Paint(buf, job.Workers), stats, nil
+}
+
+// Compute computes the iteration data of job without coloring it, see
+// Coloring.Paint.
+func Compute(ctx context.Context, job Job) (*Buffer, Stats, error) {
+	stats := Stats{MaxIter: job.MaxIter, Pixels: job.Width * job.Height}
+	if err := job.validate(); err != nil {
+		return nil, stats, err
+	}
+	workers := job.Workers
+	if workers == 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if job.BigView != nil {
+		job.View = job.BigView.Rectangle()
+	}
+	prec := job.precision()
+
+	points := make([]fractal.Point, job.Width*job.Height)
+	switch {
+	case prec > 0:
+		renderPerturbation(ctx, &job, prec, workers, points, &stats)
+	case job.Trap != nil && job.Kernel == nil:
+		renderTiles(ctx, &job, workers, points)
+	case job.Strategy == Subdivide || job.Strategy == SubdivideInterior && job.connected():
+		renderSubdivide(ctx, &job, workers, points)
+	default:
+		renderTiles(ctx, &job, workers, points)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, stats, err
+	}
+
+	buf := NewBuffer(job.Width, job.Height, job.MaxIter)
+	if newton, ok := job.Kernel.(*fractal.Newton); ok {
+		buf.Roots = len(newton.Roots)
+	}
+	if job.Trap != nil && job.Kernel == nil {
+		buf.Trap, buf.TrapZ = make([]float64, len(points)), make([]complex128, len(points))
+	}
+	spacing := job.View.Spacing(job.Width, job.Height)
+	for i := range points {
+		buf.set(i, &points[i], spacing)
+		if !buf.Escaped[i] {
+			stats.Inside++
+		}
+	}
+	return buf, stats, nil
+}
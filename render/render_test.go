@@ -0,0 +1,128 @@
+package render
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"math"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/jfhaecker/mandelgo/fractal"
+	"github.com/jfhaecker/mandelgo/palette"
+	"github.com/jfhaecker/mandelgo/viewport"
+)
+
+func benchJob(loc viewport.Location) Job {
+	job := Job{Width: 500, Height: 500, MaxIter: 1000, BailoutRadius: 20, Palette: palette.Quake}
+	job.View.Set(complex(loc.X, loc.Y), 2*loc.R, 2*loc.R)
+	return job
+}
+
+// renderPipeline is the former pipeline: rows go to the workers over a
+// channel, every point comes back over another channel to a single
+// goroutine that paints it.
+func renderPipeline(job Job) *image.RGBA {
+	workers := runtime.GOMAXPROCS(0)
+	img := image.NewRGBA(image.Rect(0, 0, job.Width, job.Height))
+	rows := make(chan int, job.Height)
+	points := make(chan *fractal.Point, job.Height*job.Width)
+	gradient := job.Palette.Gradient()
+	var wg1, wg2 sync.WaitGroup
+	wg2.Add(1)
+	go func() {
+		defer wg2.Done()
+		for point := range points {
+			co := color.RGBA{0, 0, 0, 255}
+			if point.IterationCount != job.MaxIter {
+				co = gradient.Iteration(point.NormIterationCount)
+			}
+			img.SetRGBA(point.X, point.Y, co)
+		}
+	}()
+	for i := 0; i < workers; i++ {
+		wg1.Add(1)
+		go func() {
+			defer wg1.Done()
+			kernel := job.kernel()
+			for y := range rows {
+				for x := 0; x < job.Width; x++ {
+					z := job.View.At(x, y, job.Width, job.Height)
+					points <- kernel.Iterate(&fractal.Point{Z: z, X: x, Y: y}, job.MaxIter)
+				}
+			}
+		}()
+	}
+	for y := 0; y < job.Height; y++ {
+		rows <- y
+	}
+	close(rows)
+	wg1.Wait()
+	close(points)
+	wg2.Wait()
+	return img
+}
+
+var benchLocations = []struct {
+	name string
+	loc  viewport.Location
+}{
+	{"full", viewport.Location{X: -0.75, Y: 0, R: 1.5}},
+	{"location-6", viewport.Locations[6]},
+	{"location-18", viewport.Locations[18]},
+}
+
+func BenchmarkTiles(b *testing.B) {
+	for _, l := range benchLocations {
+		b.Run(l.name, func(b *testing.B) {
+			job := benchJob(l.loc)
+			for i := 0; i < b.N; i++ {
+				if _, err := Render(context.Background(), job); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkPipeline(b *testing.B) {
+	for _, l := range benchLocations {
+		b.Run(l.name, func(b *testing.B) {
+			job := benchJob(l.loc)
+			for i := 0; i < b.N; i++ {
+				renderPipeline(job)
+			}
+		})
+	}
+}
+
+func TestTilesMatchPipeline(t *testing.T) {
+	for _, l := range benchLocations {
+		job := benchJob(l.loc)
+		job.Width, job.Height = 203, 157 // tiles that do not fit
+		want := renderPipeline(job)
+		for _, workers := range []int{1, 3, 8} {
+			job.Workers = workers
+			got, err := Render(context.Background(), job)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got.Pix) != string(want.Pix) {
+				t.Errorf("%v with %v workers: tiles differ from the pipeline", l.name, workers)
+			}
+		}
+	}
+}
+
+// The cycle detection tolerance is given in pixels.
+func TestPeriodicityScales(t *testing.T) {
+	job := Job{Width: 301, Height: 301, Periodicity: 1e-9}
+	for _, width := range []float64{3, 3e-10} {
+		job.View.Set(-0.5, width, width)
+		want := 1e-9 * width / 300
+		if got := job.kernel().(fractal.Escape).Periodicity; math.Abs(got-want) > 1e-12*want {
+			t.Errorf("width %v: tolerance %v, want %v", width, got, want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package render
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jfhaecker/mandelgo/fractal"
+)
+
+// tileSize is the edge length of the square tiles of the scheduler.
+const tileSize = 32
+
+// tileQueue is the deque of tiles of a worker. The owner takes tiles from
+// the back, other workers steal from the front.
+type tileQueue struct {
+	mu    sync.Mutex
+	tiles []tile
+}
+
+func (q *tileQueue) pop() (tile, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.tiles) == 0 {
+		return tile{}, false
+	}
+	t := q.tiles[len(q.tiles)-1]
+	q.tiles = q.tiles[:len(q.tiles)-1]
+	return t, true
+}
+
+// steal takes the front half of the tiles, at least one.
+func (q *tileQueue) steal() []tile {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := (len(q.tiles) + 1) / 2
+	stolen := append([]tile(nil), q.tiles[:n]...)
+	q.tiles = q.tiles[n:]
+	return stolen
+}
+
+func (q *tileQueue) push(tiles []tile) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.tiles = append(q.tiles, tiles...)
+}
+
+// renderTiles computes every pixel of job into points. Each worker starts
+// with a band of square tiles. The inside of the set takes much longer than
+// the outside, so a worker that runs out of tiles steals half of the
+// remaining ones of another worker.
+// https://en.wikipedia.org/wiki/Work_stealing
+func renderTiles(ctx context.Context, job *Job, workers int, points []fractal.Point) {
+	var tiles []tile
+	for y := 0; y < job.Height; y += tileSize {
+		for x := 0; x < job.Width; x += tileSize {
+			tiles = append(tiles, tile{x, y, min(x+tileSize, job.Width) - 1, min(y+tileSize, job.Height) - 1})
+		}
+	}
+	queues := newTileQueues(tiles, workers)
+
+	var wg sync.WaitGroup
+	for w := range queues {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			kernel := job.kernel()
+			own := &queues[w]
+			for ctx.Err() == nil {
+				t, ok := own.pop()
+				if !ok {
+					if !stealTiles(queues, w) {
+						return
+					}
+					continue
+				}
+				computeTile(job, kernel, t, points)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// newTileQueues spreads tiles over workers queues in bands. The bands are
+// full slices, a push must not append into the band of the next queue.
+func newTileQueues(tiles []tile, workers int) []tileQueue {
+	queues := make([]tileQueue, workers)
+	for w := range queues {
+		lo, hi := w*len(tiles)/workers, (w+1)*len(tiles)/workers
+		queues[w].tiles = tiles[lo:hi:hi]
+	}
+	return queues
+}
+
+// stealTiles moves tiles of another worker to worker w, it reports false
+// when there is nothing left to steal.
+func stealTiles(queues []tileQueue, w int) bool {
+	for i := 1; i < len(queues); i++ {
+		if stolen := queues[(w+i)%len(queues)].steal(); len(stolen) > 0 {
+			queues[w].push(stolen)
+			return true
+		}
+	}
+	return false
+}
+
+func computeTile(job *Job, kernel fractal.Kernel, t tile, points []fractal.Point) {
+	for y := t.y0; y <= t.y1; y++ {
+		for x := t.x0; x <= t.x1; x++ {
+			p := &points[y*job.Width+x]
+			*p = fractal.Point{Z: job.View.At(x, y, job.Width, job.Height), X: x, Y: y}
+			kernel.Iterate(p, job.MaxIter)
+		}
+	}
+}
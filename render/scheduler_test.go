@@ -0,0 +1,45 @@
+package render
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jfhaecker/mandelgo/viewport"
+)
+
+// More workers than tiles make every worker steal, run it with -race.
+func TestTilesMoreWorkersThanTiles(t *testing.T) {
+	job := benchJob(viewport.Locations[6])
+	job.Width, job.Height = 200, 130 // 7x5 tiles
+	job.Workers = 1
+	want, err := Render(context.Background(), job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, workers := range []int{5, 13, 35, 44} {
+		job.Workers = workers
+		got, err := Render(context.Background(), job)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got.Pix) != string(want.Pix) {
+			t.Errorf("%v workers: image differs from a single worker", workers)
+		}
+	}
+}
+
+func TestTileQueuesDoNotShare(t *testing.T) {
+	tiles := make([]tile, 10)
+	for i := range tiles {
+		tiles[i] = tile{x0: i}
+	}
+	queues := newTileQueues(tiles, 3)
+	queues[0].push([]tile{{x0: -1}})
+	for w := 1; w < len(queues); w++ {
+		for _, tl := range queues[w].tiles {
+			if tl.x0 < 0 {
+				t.Fatalf("a push to queue 0 overwrote a tile of queue %v", w)
+			}
+		}
+	}
+}
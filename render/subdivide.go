@@ -0,0 +1,230 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/jfhaecker/mandelgo/fractal"
+)
+
+// Strategy decides how the pixels of an image are computed.
+type Strategy int
+
+const (
+	// Tiles computes every pixel, square tiles are spread over the workers.
+	// It used to hand out rows and was called rows, the name is still
+	// understood.
+	Tiles Strategy = iota
+	// Subdivide fills rectangles whose border has the same iteration count
+	// without computing their inside, the smooth iteration count is
+	// interpolated from the border. Other rectangles are split in four.
+	// With Job.Distance it also fills rectangles the distance estimate of a
+	// corner proves to be far outside of the set. With Job.Trap every
+	// pixel is computed like Tiles, the traps do not follow the iteration
+	// count, not even inside of the set.
+	// https://mrob.com/pub/muency/marianisilveralgorithm.html
+	Subdivide
+	// SubdivideInterior is Subdivide that only fills rectangles whose border
+	// is all MaxIter. Since the Mandelbrot set is connected those are inside
+	// of it, only filaments thinner than a pixel can slip through the border.
+	// Julia sets, other formulas and custom Kernels need not be connected,
+	// they are computed like Tiles, so are jobs with a Trap.
+	SubdivideInterior
+)
+
+// StrategyNames lists the names understood by StrategyByName.
+var StrategyNames = []string{"tiles", "subdivide", "subdivide-interior"}
+
+func StrategyByName(name string) (Strategy, error) {
+	if name == "rows" {
+		return Tiles, nil
+	}
+	for i, n := range StrategyNames {
+		if n == name {
+			return Strategy(i), nil
+		}
+	}
+	return Tiles, fmt.Errorf("unknown strategy %q", name)
+}
+
+func (s Strategy) String() string {
+	if s < Tiles || s > SubdivideInterior {
+		return fmt.Sprintf("Strategy(%d)", int(s))
+	}
+	return StrategyNames[s]
+}
+
+const (
+	// subdivideTile is the size of the tiles the workers start with.
+	subdivideTile = 64
+	// subdivideMin is the size below which a tile is computed pixel by pixel.
+	subdivideMin = 4
+)
+
+// tile is a rectangle of pixels including its border.
+type tile struct {
+	x0, y0, x1, y1 int
+}
+
+// subdivider computes an image with Mariani-Silver subdivision. The border
+// of a tile is always computed before the tile is handed out, so every pixel
+// is only written by one worker.
+type subdivider struct {
+	job    *Job
+	points []fractal.Point
+	done   []bool
+}
+
+func renderSubdivide(ctx context.Context, job *Job, workers int, points []fractal.Point) {
+	s := &subdivider{
+		job:    job,
+		points: points,
+		done:   make([]bool, job.Width*job.Height),
+	}
+
+	// the grid lines between the tiles
+	var xs, ys []int
+	for x := 0; x < job.Width-1; x += subdivideTile {
+		xs = append(xs, x)
+	}
+	for y := 0; y < job.Height-1; y += subdivideTile {
+		ys = append(ys, y)
+	}
+	xs, ys = append(xs, job.Width-1), append(ys, job.Height-1)
+	parallel(ctx, workers, len(ys), func(i int) {
+		s.rect(0, ys[i], job.Width-1, ys[i], job.kernel())
+	})
+	parallel(ctx, workers, len(xs), func(i int) {
+		s.rect(xs[i], 0, xs[i], job.Height-1, job.kernel())
+	})
+
+	var tiles []tile
+	for j := 0; j+1 < len(ys); j++ {
+		for i := 0; i+1 < len(xs); i++ {
+			tiles = append(tiles, tile{xs[i], ys[j], xs[i+1], ys[j+1]})
+		}
+	}
+	parallel(ctx, workers, len(tiles), func(i int) {
+		s.tile(tiles[i], job.kernel())
+	})
+}
+
+// rect computes the pixels from x0, y0 to x1, y1 that are not done yet.
+func (s *subdivider) rect(x0, y0, x1, y1 int, kernel fractal.Kernel) {
+	w, h := s.job.Width, s.job.Height
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			i := y*w + x
+			if s.done[i] {
+				continue
+			}
+			s.points[i] = fractal.Point{Z: s.job.View.At(x, y, w, h), X: x, Y: y}
+			kernel.Iterate(&s.points[i], s.job.MaxIter)
+			s.done[i] = true
+		}
+	}
+}
+
+func (s *subdivider) tile(t tile, kernel fractal.Kernel) {
+	if t.x1-t.x0 <= subdivideMin || t.y1-t.y0 <= subdivideMin {
+		s.rect(t.x0+1, t.y0+1, t.x1-1, t.y1-1, kernel)
+		return
+	}
+	if s.uniform(t) || s.exterior(t) {
+		s.fill(t)
+		return
+	}
+	mx, my := (t.x0+t.x1)/2, (t.y0+t.y1)/2
+	s.rect(t.x0+1, my, t.x1-1, my, kernel)
+	s.rect(mx, t.y0+1, mx, t.y1-1, kernel)
+	s.tile(tile{t.x0, t.y0, mx, my}, kernel)
+	s.tile(tile{mx, t.y0, t.x1, my}, kernel)
+	s.tile(tile{t.x0, my, mx, t.y1}, kernel)
+	s.tile(tile{mx, my, t.x1, t.y1}, kernel)
+}
+
+// uniform tells if all border pixels of t have the same iteration count and
+// root, with SubdivideInterior they also have to be MaxIter.
+func (s *subdivider) uniform(t tile) bool {
+	w := s.job.Width
+	first := &s.points[t.y0*w+t.x0]
+	if s.job.Strategy == SubdivideInterior && first.IterationCount != s.job.MaxIter {
+		return false
+	}
+	same := func(x, y int) bool {
+		p := &s.points[y*w+x]
+		return p.IterationCount == first.IterationCount && p.Root == first.Root
+	}
+	for x := t.x0; x <= t.x1; x++ {
+		if !same(x, t.y0) || !same(x, t.y1) {
+			return false
+		}
+	}
+	for y := t.y0; y <= t.y1; y++ {
+		if !same(t.x0, y) || !same(t.x1, y) {
+			return false
+		}
+	}
+	return true
+}
+
+// exteriorMargin is how many times the disc known to be outside of the set
+// has to cover a tile before it is filled, the smooth iteration count is
+// only close to linear far from the set.
+const exteriorMargin = 1
+
+// exterior tells if the distance estimate of a corner proves that t lies
+// outside of the set. Nothing of the set is closer to a point than half of
+// its estimate, see fractal.Distance.
+// https://en.wikipedia.org/wiki/Koebe_quarter_theorem
+func (s *subdivider) exterior(t tile) bool {
+	if !s.job.Distance || s.job.Strategy != Subdivide {
+		return false
+	}
+	w, h := s.job.Width, s.job.Height
+	diagonal := math.Hypot(float64(t.x1-t.x0), float64(t.y1-t.y0)) * s.job.View.Spacing(w, h)
+	for _, i := range []int{t.y0*w + t.x0, t.y0*w + t.x1, t.y1*w + t.x0, t.y1*w + t.x1} {
+		if s.points[i].Distance/2 >= exteriorMargin*diagonal {
+			return true
+		}
+	}
+	return false
+}
+
+// fill sets the inside of t from its border. The smooth iteration count is
+// the mean of the horizontal and the vertical interpolation between the
+// border pixels, so colors stay smooth. The distance estimate is
+// interpolated the same way.
+func (s *subdivider) fill(t tile) {
+	w, h := s.job.Width, s.job.Height
+	at := func(x, y int) float64 { return s.points[y*w+x].NormIterationCount }
+	dist := func(x, y int) float64 { return s.points[y*w+x].Distance }
+	first := s.points[t.y0*w+t.x0]
+	for y := t.y0 + 1; y < t.y1; y++ {
+		v := float64(y-t.y0) / float64(t.y1-t.y0)
+		for x := t.x0 + 1; x < t.x1; x++ {
+			u := float64(x-t.x0) / float64(t.x1-t.x0)
+			i := y*w + x
+			p := fractal.Point{
+				Z:              s.job.View.At(x, y, w, h),
+				IterationCount: first.IterationCount,
+				X:              x,
+				Y:              y,
+				Root:           first.Root,
+			}
+			if p.IterationCount != s.job.MaxIter {
+				horizontal := (1-u)*at(t.x0, y) + u*at(t.x1, y)
+				vertical := (1-v)*at(x, t.y0) + v*at(x, t.y1)
+				p.NormIterationCount = (horizontal + vertical) / 2
+				if s.job.Distance {
+					horizontal = (1-u)*dist(t.x0, y) + u*dist(t.x1, y)
+					vertical = (1-v)*dist(x, t.y0) + v*dist(x, t.y1)
+					p.Distance = (horizontal + vertical) / 2
+				}
+			}
+			s.points[i] = p
+			s.done[i] = true
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package render
+
+import (
+	"context"
+	"image/color"
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/jfhaecker/mandelgo/fractal"
+	"github.com/jfhaecker/mandelgo/palette"
+	"github.com/jfhaecker/mandelgo/viewport"
+)
+
+func compute(t *testing.T, job Job, strategy Strategy) *Buffer {
+	job.Strategy = strategy
+	buf, _, err := Compute(context.Background(), job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
+
+// subdivideViews show large parts of the set next to slowly escaping points.
+var subdivideViews = []viewport.Location{
+	{X: -0.75, Y: 0, R: 1.5},
+	viewport.Locations[6],
+	{X: -0.1, Y: 0.9, R: 0.15},
+}
+
+// SubdivideInterior gives the same points as Tiles at these views, Subdivide
+// keeps the set and interpolates the smooth iteration count within one
+// iteration.
+func TestSubdivide(t *testing.T) {
+	for _, loc := range subdivideViews {
+		job := Job{Width: 200, Height: 150, MaxIter: 500, BailoutRadius: 20, Palette: palette.Quake, Workers: 2}
+		job.View.Set(complex(loc.X, loc.Y), 2*loc.R, 1.5*loc.R)
+		tiles := compute(t, job, Tiles)
+
+		interior := compute(t, job, SubdivideInterior)
+		for i := range tiles.Smooth {
+			if interior.Escaped[i] != tiles.Escaped[i] || interior.Smooth[i] != tiles.Smooth[i] {
+				t.Errorf("%v: subdivide-interior pixel %v: %v (escaped %v), tiles %v (escaped %v)", loc,
+					i, interior.Smooth[i], interior.Escaped[i], tiles.Smooth[i], tiles.Escaped[i])
+				break
+			}
+		}
+
+		sub := compute(t, job, Subdivide)
+		for i := range tiles.Smooth {
+			if sub.Escaped[i] != tiles.Escaped[i] || math.Abs(sub.Smooth[i]-tiles.Smooth[i]) > 1 {
+				t.Errorf("%v: subdivide pixel %v: %v (escaped %v), tiles %v (escaped %v)", loc,
+					i, sub.Smooth[i], sub.Escaped[i], tiles.Smooth[i], tiles.Escaped[i])
+				break
+			}
+		}
+	}
+}
+
+// annulus is a set with a hole, the points with 0.05 < |z| < 1.
+type annulus struct{}
+
+func (annulus) Iterate(p *fractal.Point, maxIter int) *fractal.Point {
+	p.IterationCount = maxIter
+	if r := cmplx.Abs(p.Z); r <= 0.05 || r >= 1 {
+		p.IterationCount, p.NormIterationCount = 1, 1
+	}
+	return p
+}
+
+// Sets that need not be connected are not filled, the hole of the annulus
+// is smaller than a tile.
+func TestSubdivideInteriorDisconnected(t *testing.T) {
+	job := Job{Width: 200, Height: 200, MaxIter: 100, BailoutRadius: 20, Palette: palette.Quake, Kernel: annulus{}}
+	job.View.Set(0, 2.4, 2.4)
+	tiles := compute(t, job, Tiles)
+	interior := compute(t, job, SubdivideInterior)
+	for i := range tiles.Smooth {
+		if interior.Escaped[i] != tiles.Escaped[i] {
+			t.Fatalf("pixel %v of the hole was filled", i)
+		}
+	}
+	for _, j := range []Job{{Julia: true}, {Formula: fractal.BurningShip{}}} {
+		if j.connected() {
+			t.Errorf("%+v is taken as connected", j)
+		}
+	}
+}
+
+// Every pixel of a job with a trap has the trap data of its orbit, inside of
+// the main cardioid and with Subdivide too.
+func TestTrapInterior(t *testing.T) {
+	job := Job{Width: 200, Height: 150, MaxIter: 200, BailoutRadius: 20, Palette: palette.Quake,
+		Trap: fractal.PointTrap{Center: 5}}
+	job.View.Set(-0.75, 3, 2.25)
+	tiles := compute(t, job, Tiles)
+	sub := compute(t, job, Subdivide)
+	for i, d := range tiles.Trap {
+		if d == 0 || math.IsInf(d, 1) {
+			t.Fatalf("pixel %v: trap %v", i, d)
+		}
+		if sub.Trap[i] != d {
+			t.Fatalf("pixel %v: subdivide trap %v, tiles %v", i, sub.Trap[i], d)
+		}
+	}
+
+	c := Coloring{Gradient: palette.Quake.Gradient(), Trap: job.Trap}
+	for i := range tiles.Trap {
+		if !tiles.Escaped[i] {
+			if got := c.color(tiles, i); got == (color.RGBA{0, 0, 0, 255}) {
+				t.Errorf("inside pixel %v is black", i)
+			}
+			tiles.Trap[i] = math.Inf(1)
+			if got := c.color(tiles, i); got != (color.RGBA{0, 0, 0, 255}) {
+				t.Errorf("inside pixel %v that missed the trap is %v", i, got)
+			}
+			break
+		}
+	}
+}
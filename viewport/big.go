@@ -0,0 +1,131 @@
+package viewport
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/jfhaecker/mandelgo/floatexp"
+)
+
+// precisionMargin is the number of bits kept beyond the pixel spacing, the
+// iteration amplifies rounding errors of the coordinates.
+const precisionMargin = 12
+
+// BigPoint is a point of the complex plane with arbitrary precision.
+type BigPoint struct {
+	X, Y *big.Float
+}
+
+func NewBigPoint(c complex128) BigPoint {
+	return BigPoint{big.NewFloat(real(c)), big.NewFloat(imag(c))}
+}
+
+// ParseBigFloat parses a decimal number keeping all of its digits.
+func ParseBigFloat(s string) (*big.Float, error) {
+	s = strings.TrimSpace(s)
+	prec := uint(float64(len(s))*math.Log2(10)) + 64
+	f, _, err := big.ParseFloat(s, 10, prec, big.ToNearestEven)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a number", s)
+	}
+	return f, nil
+}
+
+// ParseBigPoint parses a complex number like -0.75+0.1i keeping all digits.
+func ParseBigPoint(s string) (BigPoint, error) {
+	s = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(s), "("), ")")
+	re, im := s, "0"
+	if strings.HasSuffix(s, "i") {
+		re, im = "0", strings.TrimSuffix(s, "i")
+		for i := len(s) - 2; i > 0; i-- {
+			if (s[i] == '+' || s[i] == '-') && s[i-1] != 'e' && s[i-1] != 'E' {
+				re, im = s[:i], s[i:len(s)-1]
+				break
+			}
+		}
+		switch im {
+		case "", "+":
+			im = "1"
+		case "-":
+			im = "-1"
+		}
+	}
+	x, err := ParseBigFloat(re)
+	if err != nil {
+		return BigPoint{}, err
+	}
+	y, err := ParseBigFloat(im)
+	if err != nil {
+		return BigPoint{}, err
+	}
+	return BigPoint{x, y}, nil
+}
+
+func (p BigPoint) Complex() complex128 {
+	x, _ := p.X.Float64()
+	y, _ := p.Y.Float64()
+	return complex(x, y)
+}
+
+// BigRectangle is a Rectangle around an arbitrary precision center. Width
+// and height only need the range of floatexp, not the precision.
+type BigRectangle struct {
+	Center        BigPoint
+	Width, Height floatexp.Float
+}
+
+// Spacing returns the distance between two pixels.
+func (r *BigRectangle) Spacing(imageWidth, imageHeight int) floatexp.Float {
+	dx := r.Width.Div(floatexp.New(float64(imageWidth - 1)))
+	dy := r.Height.Div(floatexp.New(float64(imageHeight - 1)))
+	if dx.Cmp(dy) < 0 {
+		return dx
+	}
+	return dy
+}
+
+// Precision returns the number of mantissa bits needed to tell the pixels of
+// an image of the given size apart, or 0 if float64 is good enough.
+func (r *BigRectangle) Precision(imageWidth, imageHeight int) uint {
+	c := r.Center.Complex()
+	magnitude := math.Max(1, math.Max(math.Abs(real(c)), math.Abs(imag(c))))
+	bits := math.Ceil(math.Log2(magnitude)-r.Spacing(imageWidth, imageHeight).Log2()) + precisionMargin
+	if bits <= 53 {
+		return 0
+	}
+	return uint(bits) + 32
+}
+
+// Rectangle rounds r to float64.
+func (r *BigRectangle) Rectangle() Rectangle {
+	rect := Rectangle{}
+	rect.Set(r.Center.Complex(), r.Width.Float64(), r.Height.Float64())
+	return rect
+}
+
+// Offset returns the distance of pixel x, y from the center. It maps pixels
+// the same way as Rectangle.At.
+func (r *BigRectangle) Offset(x, y, imageWidth, imageHeight int) floatexp.Complex {
+	half := floatexp.New(0.5)
+	w, h := r.Width.Mul(half), r.Height.Mul(half)
+	dx := linspaceExp(w.Neg(), w, imageWidth, x)
+	dy := linspaceExp(h, h.Neg(), imageHeight, y)
+	return floatexp.ComplexOf(dx, dy)
+}
+
+// linspaceExp is Linspace with the same rounding in floatexp.
+func linspaceExp(start, end floatexp.Float, num int, i int) floatexp.Float {
+	step := end.Sub(start).Div(floatexp.New(float64(num - 1)))
+	return start.Add(step.Mul(floatexp.New(float64(i))))
+}
+
+// At returns pixel x, y with prec bits of precision.
+func (r *BigRectangle) At(x, y, imageWidth, imageHeight int, prec uint) BigPoint {
+	d := r.Offset(x, y, imageWidth, imageHeight)
+	return BigPoint{
+		new(big.Float).SetPrec(prec).Add(r.Center.X, d.Real().Big()),
+		new(big.Float).SetPrec(prec).Add(r.Center.Y, d.Imag().Big()),
+	}
+}
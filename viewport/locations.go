@@ -0,0 +1,96 @@
+package viewport
+
+type Location struct {
+	X float64
+	Y float64
+	R float64
+	// exact decimal coordinates for locations deeper than float64
+	ExactX, ExactY string
+}
+
+// Center returns the center of l with all known digits.
+func (l Location) Center() BigPoint {
+	if l.ExactX == "" {
+		return NewBigPoint(complex(l.X, l.Y))
+	}
+	x, _ := ParseBigFloat(l.ExactX)
+	y, _ := ParseBigFloat(l.ExactY)
+	return BigPoint{x, y}
+}
+
+//http://fractaljourney.blogspot.com/2010/01/mandelbrot-ultra-zoom-5-21e275.html,},
+//http://www.cuug.ab.ca/dewara/mandelbrot/Mandelbrowser.html
+var (
+	Locations = []Location{
+		Location{
+			X:      -1.740062382579339905220844167065825638296641720436171866879862418461182919644153056054840718339483225743450008259172138785492983677893366503417299549623738838303346465461290768441055486136870719850559269507357211790243666940134793753068611574745943820712885258222629105433648695946003865,
+			Y:      0.0281753397792110489924115211443195096875390767429906085704013095958801743240920186385400814658560553615695084486774077000669037710191665338060418999324320867147028768983704831316527873719459264592084600433150333362859318102017032958074799966721030307082150171994798478089798638258639934,
+			R:      0.1e-5,
+			ExactX: "-1.740062382579339905220844167065825638296641720436171866879862418461182919644153056054840718339483225743450008259172138785492983677893366503417299549623738838303346465461290768441055486136870719850559269507357211790243666940134793753068611574745943820712885258222629105433648695946003865",
+			ExactY: "0.0281753397792110489924115211443195096875390767429906085704013095958801743240920186385400814658560553615695084486774077000669037710191665338060418999324320867147028768983704831316527873719459264592084600433150333362859318102017032958074799966721030307082150171994798478089798638258639934"},
+		Location{X: -0.7463, Y: 0.1102, R: 0.005},
+		Location{X: -0.7453, Y: 0.1127, R: 6.5e-4},
+		Location{X: -0.74529, Y: 0.113075, R: 1.5e-4},
+		Location{X: -0.745428, Y: 0.113009, R: 3.0e-5},
+		Location{X: -0.16, Y: 1.0405, R: 0.026},
+		Location{X: -0.925, Y: 0.266, R: 0.032},
+		Location{X: -1.25066, Y: 0.02012, R: 1.7e-4},
+		Location{X: -0.748, Y: 0.1, R: 0.0014},
+		Location{X: -0.235125, Y: 0.827215, R: 4.0e-5},
+		Location{X: -0.722, Y: 0.246, R: 0.019},
+		Location{X: -1.315180982097868, Y: 0.073481649996795, R: 1.0e-13},
+		Location{X: -0.156653458, Y: 1.039128122, R: 2.0e-9},
+		Location{X: -0.15680460, Y: 1.03902070, R: 1.0e-12},
+		Location{X: -0.16070135, Y: 1.0375665, R: 1.0e-7},
+		Location{X: 0.2549870375144766, Y: -0.0005679790528465, R: 1.0e-13},
+		Location{X: 0.267235642726, Y: -0.003347589624, R: 1.15e-10},
+		Location{X: -0.0452407411, Y: 0.986816213, R: 1.75e-7},
+		Location{X: -0.0452407411, Y: 0.9868162204352258, R: 4.4e-9},
+		Location{X: -0.0452407411, Y: 0.9868162204352258, R: 6.8e-10},
+		Location{X: -0.0452407411, Y: 0.9868162204352258, R: 2.7e-10},
+		Location{X: -0.04524074130409, Y: 0.9868162207157838, R: 2.3e-12},
+		Location{X: -0.04524074130409, Y: 0.9868162207157852, R: 6.8e-14},
+		Location{X: 0.281717921930775, Y: 0.5771052841488505, R: 5.15e-12},
+		Location{X: 0.281717921930775, Y: 0.5771052841488505, R: 1.25e-12},
+		Location{X: 0.281717921930775, Y: 0.5771052841488505, R: 4.75e-13},
+		Location{X: 0.281717921930775, Y: 0.5771052841488505, R: 1.78e-13},
+		Location{X: 0.281717921930775, Y: 0.5771052841488505, R: 8.5e-14},
+		Location{X: 0.281717921930775, Y: 0.5771052841488505, R: 4.0e-14},
+		Location{X: 0.281717921930775, Y: 0.5771052841488505, R: 1.92e-14},
+		Location{X: -0.840719, Y: 0.22442, R: 7.9e-5},
+		Location{X: -0.81153120295763, Y: 0.20142958206181, R: 3.0e-4},
+		Location{X: -0.81153120295763, Y: 0.20142958206181, R: 5.9e-6},
+		Location{X: -0.81153120295763, Y: 0.20142958206181, R: 4.6e-8},
+		Location{X: -0.81153120295763, Y: 0.20142958206181, R: 1.51e-9},
+		Location{X: -0.81153120295763, Y: 0.20142958206181, R: 1.12e-13},
+		Location{X: -0.8115312340458353, Y: 0.2014296112433656, R: 3.4e-14},
+		Location{X: 0.452721018749286, Y: 0.39649427698014, R: 1.1e-13},
+		Location{X: 0.45272105023, Y: 0.396494224267, R: 2.7e-9},
+		Location{X: 0.45272105023, Y: 0.396494224267, R: 3.9e-10},
+		Location{X: 0.45272105023, Y: 0.396494224267, R: 1.4e-10},
+		Location{X: -1.1533577030005, Y: 0.307486987838885, R: 5.3e-10},
+		Location{X: -1.1533577030005, Y: 0.307486987838885, R: 9.5e-14},
+		Location{X: -1.15412664822215, Y: 0.30877492767139, R: 3.1e-9},
+		Location{X: -1.15412664822215, Y: 0.30877492767139, R: 6.2e-11},
+		Location{X: -1.15412664822215, Y: 0.30877492767139, R: 9.5e-12},
+		Location{X: -1.15412664822215, Y: 0.30877492767139, R: 3.7e-12},
+		Location{X: -1.7590170270659, Y: 0.01916067191295, R: 1.1e-12},
+		Location{X: -1.99999911758738, Y: 0.0, R: 1.48e-12},
+		Location{X: -1.99999911758738, Y: 0.0, R: 5.9e-13},
+		Location{X: -1.99999911758738, Y: 0.0, R: 2.5e-13},
+		Location{X: 0.432539867562512, Y: 0.226118675951765, R: 3.2e-6},
+		Location{X: 0.432539867562512, Y: 0.226118675951765, R: 3.2e-13},
+		Location{X: 0.432539867562512, Y: 0.226118675951765, R: 7.3e-14},
+		Location{X: 0.432539867562512, Y: 0.226118675951818, R: 1.82e-14},
+		Location{X: 0.3369844464869, Y: 0.048778219666, R: 1.8e-11},
+		Location{X: 0.3369844464873, Y: 0.0487782196791, R: 4.2e-12},
+		Location{X: 0.33698444648918, Y: 0.048778219681, R: 2.1e-13},
+		Location{X: 0.2929859127507, Y: 0.6117848324958, R: 6.7e-7},
+		Location{X: 0.2929859127507, Y: 0.6117848324958, R: 8.6e-10},
+		Location{X: 0.2929859127507, Y: 0.6117848324958, R: 4.4e-11},
+		Location{X: 0.2929859127507, Y: 0.6117848324958, R: 1.0e-11},
+		Location{X: -0.936532336, Y: 0.2633616, R: 1.75e-7},
+		Location{X: -0.7336438924199521, Y: 0.2455211406714035, R: 4.3e-10},
+		Location{X: -0.7336438924199521, Y: 0.2455211406714035, R: 4.5e-14},
+	}
+)
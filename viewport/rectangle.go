@@ -0,0 +1,51 @@
+// Package viewport maps image pixels to the complex plane.
+package viewport
+
+import "math"
+
+// Rectangle is the part of the complex plane shown in an image.
+type Rectangle struct {
+	TopLeft     complex128
+	BottomRight complex128
+	Center      complex128
+	Width       float64
+	Height      float64
+}
+
+func (r *Rectangle) Set(center complex128, width, height float64) {
+	r.Center = center
+	r.Width = width
+	r.Height = height
+	r.calc()
+}
+
+// Scale shrinks the rectangle by factor around its center.
+func (r *Rectangle) Scale(factor float64) {
+	r.Width -= (r.Width * factor)
+	r.Height -= (r.Height * factor)
+	r.calc()
+}
+
+func (r *Rectangle) calc() {
+	r.TopLeft = complex(real(r.Center)-r.Width/2,
+		imag(r.Center)+r.Height/2)
+	r.BottomRight = complex(real(r.Center)+r.Width/2,
+		imag(r.Center)-r.Height/2)
+}
+
+// Spacing returns the distance between two pixels.
+func (r *Rectangle) Spacing(imageWidth, imageHeight int) float64 {
+	return math.Min(r.Width/float64(imageWidth-1), r.Height/float64(imageHeight-1))
+}
+
+// At returns the complex number of pixel x, y in an image of the given size.
+func (r *Rectangle) At(x, y, imageWidth, imageHeight int) complex128 {
+	real := Linspace(real(r.TopLeft), real(r.BottomRight), imageWidth, x)
+	imag := Linspace(imag(r.TopLeft), imag(r.BottomRight), imageHeight, y)
+	return complex(real, imag)
+}
+
+func Linspace(start, end float64, num int, i int) float64 {
+	step := (end - start) / float64(num-1)
+	return start + (step * float64(i))
+}
@@ -0,0 +1,24 @@
+package viewport
+
+import "github.com/jfhaecker/mandelgo/floatexp"
+
+// Zoom is a zoom schedule: the first frame is centered on Center and every
+// following frame is scaled down by Ratio.
+type Zoom struct {
+	Center        BigPoint
+	Width, Height floatexp.Float
+	Ratio         float64
+}
+
+// Frame returns the rectangle of frame n. The scaling is applied n times
+// instead of using a power so the result is bit for bit the same as scaling
+// a float64 rectangle frame by frame, floatexp rounds like float64.
+func (z Zoom) Frame(n int) BigRectangle {
+	r := BigRectangle{Center: z.Center, Width: z.Width, Height: z.Height}
+	ratio := floatexp.New(z.Ratio)
+	for i := 0; i < n; i++ {
+		r.Width = r.Width.Sub(r.Width.Mul(ratio))
+		r.Height = r.Height.Sub(r.Height.Mul(ratio))
+	}
+	return r
+}